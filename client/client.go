@@ -0,0 +1,436 @@
+// Package client implements My Clue BE websocket protocol for Go programs
+// such as bots, load and integration tests.
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+)
+
+// ErrDisconnected is returned by requests issued, or pending, while the client is not connected.
+var ErrDisconnected = errors.New("disconnected")
+
+// ErrTimeout is returned by requests whose response has not arrived in time.
+var ErrTimeout = errors.New("timeout")
+
+// ServerError is returned by requests the server rejected.
+// Use errors.Is to check its code, eg. errors.Is(err, client.NotYourTurn).
+type ServerError struct {
+	NotifyError
+}
+
+func (e *ServerError) Error() string {
+	if e.ErrorID != "" {
+		return fmt.Sprintf("%s (%s %s)", e.Message, e.Code, e.ErrorID)
+	}
+
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// Unwrap returns the error code.
+func (e *ServerError) Unwrap() error {
+	return e.Code
+}
+
+// Options configures a Client.
+type Options struct {
+	// URL is the server websocket url, eg. ws://127.0.0.1:8080/clue/ws
+	URL string
+	// Dialer is the websocket dialer, websocket.DefaultDialer if nil.
+	Dialer *websocket.Dialer
+	// Timeout is the maximum time waited for a response, 10 seconds if zero.
+	Timeout time.Duration
+	// Reconnect enables automatic reconnection: the client signs in again with
+	// its token and rejoins the games it was following.
+	Reconnect bool
+	// ReconnectDelay is the time waited between reconnection attempts, 1 second if zero.
+	ReconnectDelay time.Duration
+	// NotificationsBuffer is the size of the notifications channel, 64 if zero.
+	NotificationsBuffer int
+	// Language is the language of the error messages, eg. it or en-US, sent at sign in.
+	Language string
+	// OnError, if not nil, is called with the errors no request can return: notifications
+	// that can't be decoded, disconnections and failed reconnections.
+	// It is called by the client goroutines and must not block.
+	OnError func(error)
+}
+
+// Notification is a message sent by the server that is not a response to a request.
+// Body is a *MoveRecord, *NotifyUserState, *NotifyGameStarted, *NotifyFullState or
+// *NotifyAnswerOptions according to Type.
+type Notification struct {
+	GameID string
+	Seq    int
+	Type   MessageType
+	Body   interface{}
+}
+
+type response struct {
+	envelope data.Envelope
+	err      error
+}
+
+// Client is a connection to a My Clue BE server.
+// Its methods can be called concurrently.
+type Client struct {
+	opts Options
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	closed    bool
+	nextReqID int
+	pending   map[int]chan response
+	token     string
+	name      string
+	// games are the followed games and the sequence number of their last notification.
+	games map[string]int
+
+	writeMu sync.Mutex
+
+	notifications chan Notification
+}
+
+// Dial connects to a server.
+func Dial(opts Options) (*Client, error) {
+	if opts.Dialer == nil {
+		opts.Dialer = websocket.DefaultDialer
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	if opts.ReconnectDelay == 0 {
+		opts.ReconnectDelay = time.Second
+	}
+
+	if opts.NotificationsBuffer == 0 {
+		opts.NotificationsBuffer = 64
+	}
+
+	c := &Client{
+		opts:          opts,
+		pending:       make(map[int]chan response),
+		games:         make(map[string]int),
+		notifications: make(chan Notification, opts.NotificationsBuffer),
+	}
+
+	conn, err := c.dial()
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn = conn
+
+	go c.readLoop(conn)
+
+	return c, nil
+}
+
+// Notifications returns the channel notifications are delivered to.
+// It must be drained: the client stops reading from the server when it is full.
+// The channel is closed when the client is closed.
+func (c *Client) Notifications() <-chan Notification {
+	return c.notifications
+}
+
+// Token returns the token assigned by the server, empty if not signed in yet.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+// Close disconnects from the server.
+func (c *Client) Close() error {
+	c.mu.Lock()
+
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.closed = true
+	conn := c.conn
+	c.conn = nil
+
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	return conn.Close()
+}
+
+func (c *Client) dial() (*websocket.Conn, error) {
+	dialer := *c.opts.Dialer
+	dialer.Subprotocols = []string{data.ProtocolEnvelope}
+
+	conn, _, err := dialer.Dial(c.opts.URL, nil)
+
+	return conn, err
+}
+
+// call sends a request and waits for its response, decoding its body into resp if not nil.
+func (c *Client) call(messageType data.MessageType, gameID string, body interface{}, resp interface{}) error {
+	envelope := data.Envelope{
+		MessageHeader: data.MessageHeader{
+			Type:   messageType,
+			GameID: gameID,
+		},
+	}
+
+	if body != nil {
+		raw, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		envelope.Body = raw
+	}
+
+	c.mu.Lock()
+
+	conn := c.conn
+
+	if conn == nil {
+		c.mu.Unlock()
+		return ErrDisconnected
+	}
+
+	c.nextReqID++
+	envelope.ReqID = c.nextReqID
+
+	ch := make(chan response, 1)
+	c.pending[envelope.ReqID] = ch
+
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err := conn.WriteJSON(envelope)
+	c.writeMu.Unlock()
+
+	if err != nil {
+		c.forget(envelope.ReqID)
+		return err
+	}
+
+	timer := time.NewTimer(c.opts.Timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			return r.err
+		}
+
+		if r.envelope.Type == data.MessageError {
+			serverError := &ServerError{}
+
+			if err := json.Unmarshal(r.envelope.Body, &serverError.NotifyError); err != nil {
+				return err
+			}
+
+			return serverError
+		}
+
+		if resp == nil || len(r.envelope.Body) == 0 {
+			return nil
+		}
+
+		return json.Unmarshal(r.envelope.Body, resp)
+
+	case <-timer.C:
+		c.forget(envelope.ReqID)
+		return ErrTimeout
+	}
+}
+
+func (c *Client) forget(reqID int) {
+	c.mu.Lock()
+	delete(c.pending, reqID)
+	c.mu.Unlock()
+}
+
+func (c *Client) readLoop(conn *websocket.Conn) {
+	for {
+		envelope := data.Envelope{}
+
+		if err := conn.ReadJSON(&envelope); err != nil {
+			c.disconnected(conn, err)
+			return
+		}
+
+		if envelope.ReqID != 0 {
+			c.mu.Lock()
+			ch := c.pending[envelope.ReqID]
+			delete(c.pending, envelope.ReqID)
+			c.mu.Unlock()
+
+			if ch != nil {
+				ch <- response{envelope: envelope}
+			}
+
+			continue
+		}
+
+		notification, err := decodeNotification(envelope)
+
+		if err != nil {
+			c.report(fmt.Errorf("cannot decode notification %s: %w", envelope.Type, err))
+			continue
+		}
+
+		if notification.GameID != "" && notification.Seq > 0 {
+			c.mu.Lock()
+			// answer options can be sent again with an older seq, while the notifications
+			// following a join can arrive before JoinGame follows the game
+			if notification.Seq > c.games[notification.GameID] {
+				c.games[notification.GameID] = notification.Seq
+			}
+			c.mu.Unlock()
+		}
+
+		c.notifications <- notification
+	}
+}
+
+func decodeNotification(envelope data.Envelope) (Notification, error) {
+	notification := Notification{
+		GameID: envelope.GameID,
+		Seq:    envelope.Seq,
+		Type:   envelope.Type,
+	}
+
+	switch envelope.Type {
+	case MessageNotifyMoveRecord:
+		notification.Body = &MoveRecord{}
+	case MessageNotifyUserState:
+		notification.Body = &NotifyUserState{}
+	case MessageNotifyGameStarted:
+		notification.Body = &NotifyGameStarted{}
+	case MessageNotifyFullState:
+		notification.Body = &NotifyFullState{}
+	case MessageNotifyAnswerOptions:
+		notification.Body = &NotifyAnswerOptions{}
+	default:
+		var body interface{}
+		notification.Body = &body
+	}
+
+	if len(envelope.Body) == 0 {
+		return notification, nil
+	}
+
+	err := json.Unmarshal(envelope.Body, notification.Body)
+
+	return notification, err
+}
+
+// disconnected fails pending requests and, if enabled, starts reconnecting.
+func (c *Client) disconnected(conn *websocket.Conn, err error) {
+	c.mu.Lock()
+
+	if c.conn == conn {
+		c.conn = nil
+	}
+
+	pending := c.pending
+	c.pending = make(map[int]chan response)
+	closed := c.closed
+
+	c.mu.Unlock()
+
+	for _, ch := range pending {
+		ch <- response{err: ErrDisconnected}
+	}
+
+	if closed || !c.opts.Reconnect {
+		if !closed {
+			c.report(fmt.Errorf("disconnected: %w", err))
+		}
+
+		close(c.notifications)
+
+		return
+	}
+
+	go c.reconnect()
+}
+
+func (c *Client) reconnect() {
+	for {
+		time.Sleep(c.opts.ReconnectDelay)
+
+		c.mu.Lock()
+		closed := c.closed
+		c.mu.Unlock()
+
+		if closed {
+			close(c.notifications)
+			return
+		}
+
+		conn, err := c.dial()
+
+		if err != nil {
+			c.report(fmt.Errorf("reconnection failed: %w", err))
+			continue
+		}
+
+		c.mu.Lock()
+
+		if c.closed {
+			c.mu.Unlock()
+			conn.Close()
+			close(c.notifications)
+			return
+		}
+
+		c.conn = conn
+		token := c.token
+		name := c.name
+		games := make(map[string]int, len(c.games))
+		for gameID, seq := range c.games {
+			games[gameID] = seq
+		}
+		c.mu.Unlock()
+
+		go c.readLoop(conn)
+
+		if token == "" {
+			return
+		}
+
+		if _, err := c.SignIn(name); err != nil {
+			c.report(fmt.Errorf("sign in after reconnection failed: %w", err))
+			conn.Close()
+			return
+		}
+
+		for gameID, seq := range games {
+			if _, err := c.joinGame(gameID, seq); err != nil {
+				c.report(fmt.Errorf("rejoin of game %s failed: %w", gameID, err))
+			}
+		}
+
+		return
+	}
+}
+
+// report passes an error to Options.OnError, if any.
+func (c *Client) report(err error) {
+	if c.opts.OnError != nil {
+		c.opts.OnError(err)
+	}
+}
@@ -0,0 +1,314 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+)
+
+// fakeServer accepts websocket connections and hands each one, along with its index
+// starting from 0, to handle.
+func fakeServer(t *testing.T, handle func(conn *websocket.Conn, index int)) Options {
+	upgrader := websocket.Upgrader{Subprotocols: []string{data.ProtocolEnvelope}}
+
+	var connections atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			t.Error("upgrade failed:", err)
+			return
+		}
+
+		defer conn.Close()
+
+		handle(conn, int(connections.Add(1))-1)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return Options{
+		URL:     "ws" + strings.TrimPrefix(server.URL, "http"),
+		Timeout: 5 * time.Second,
+	}
+}
+
+func read(conn *websocket.Conn) (data.Envelope, error) {
+	envelope := data.Envelope{}
+
+	err := conn.ReadJSON(&envelope)
+
+	return envelope, err
+}
+
+func send(conn *websocket.Conn, header data.MessageHeader, body interface{}) error {
+	envelope := data.Envelope{MessageHeader: header}
+
+	if body != nil {
+		raw, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		envelope.Body = raw
+	}
+
+	return conn.WriteJSON(envelope)
+}
+
+func dial(t *testing.T, opts Options) *Client {
+	c, err := Dial(opts)
+
+	if err != nil {
+		t.Fatal("dial failed:", err)
+	}
+
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+func TestResponsesMatchRequests(t *testing.T) {
+	opts := fakeServer(t, func(conn *websocket.Conn, index int) {
+		// the responses are sent in reverse order, a notification in between
+		var requests []data.Envelope
+
+		for len(requests) < 2 {
+			req, err := read(conn)
+
+			if err != nil {
+				return
+			}
+
+			requests = append(requests, req)
+		}
+
+		for i := len(requests) - 1; i >= 0; i-- {
+			req := requests[i]
+
+			switch req.Type {
+			case data.MessageSignInRequest:
+				send(conn, data.MessageHeader{Type: data.MessageSignInResponse, ReqID: req.ReqID}, SignInResponse{Token: "token"})
+			case data.MessageCreateGameRequest:
+				send(conn, data.MessageHeader{Type: data.MessageCreateGameResponse, ReqID: req.ReqID}, CreateGameResponse{GameID: "g1", MyID: 1})
+			}
+
+			if i == 1 {
+				send(conn, data.MessageHeader{Type: data.MessageNotifyUserState, GameID: "g1", Seq: 1}, NotifyUserState{ID: 2, Name: "bob"})
+			}
+		}
+
+		req, err := read(conn)
+
+		if err != nil {
+			return
+		}
+
+		send(conn, data.MessageHeader{Type: data.MessageError, ReqID: req.ReqID}, NotifyError{Code: NotYourTurn, Message: "not your turn", ReqID: req.ReqID})
+
+		read(conn)
+	})
+
+	c := dial(t, opts)
+
+	signedIn := make(chan error, 1)
+
+	go func() {
+		resp, err := c.SignIn("alice")
+
+		if err == nil && resp.Token != "token" {
+			err = errors.New("unexpected token " + resp.Token)
+		}
+
+		signedIn <- err
+	}()
+
+	created, err := c.CreateGame()
+
+	if err != nil {
+		t.Fatal("create game failed:", err)
+	}
+
+	if created.GameID != "g1" || created.MyID != 1 {
+		t.Errorf("create game response %+v, want game g1 and player 1", created)
+	}
+
+	if err := <-signedIn; err != nil {
+		t.Fatal("sign in failed:", err)
+	}
+
+	if c.Token() != "token" {
+		t.Errorf("token %q, want token", c.Token())
+	}
+
+	notification := <-c.Notifications()
+
+	if userState, ok := notification.Body.(*NotifyUserState); !ok || notification.GameID != "g1" || notification.Seq != 1 || userState.Name != "bob" {
+		t.Errorf("notification %+v, want bob state in g1 with seq 1", notification)
+	}
+
+	err = c.VoteStart("g1", true)
+
+	var serverError *ServerError
+
+	if !errors.Is(err, NotYourTurn) || !errors.As(err, &serverError) || serverError.Message != "not your turn" {
+		t.Errorf("vote start error %v, want %s", err, NotYourTurn)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	late := make(chan struct{})
+
+	opts := fakeServer(t, func(conn *websocket.Conn, index int) {
+		req, err := read(conn)
+
+		if err != nil {
+			return
+		}
+
+		<-late
+
+		// the response arrives after the request timed out and must be ignored
+		send(conn, data.MessageHeader{Type: data.MessageEmptyResponse, ReqID: req.ReqID}, nil)
+
+		req, err = read(conn)
+
+		if err != nil {
+			return
+		}
+
+		send(conn, data.MessageHeader{Type: data.MessageEmptyResponse, ReqID: req.ReqID}, nil)
+
+		read(conn)
+	})
+
+	opts.Timeout = 50 * time.Millisecond
+
+	c := dial(t, opts)
+
+	if err := c.RollDices("g1"); err != ErrTimeout {
+		t.Errorf("roll dices error %v, want %v", err, ErrTimeout)
+	}
+
+	close(late)
+
+	if err := c.Pass("g1"); err != nil {
+		t.Errorf("pass failed: %v", err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	type rejoin struct {
+		token string
+		req   data.JoinGameRequest
+	}
+
+	rejoined := make(chan rejoin, 1)
+
+	opts := fakeServer(t, func(conn *websocket.Conn, index int) {
+		var token string
+
+		for {
+			req, err := read(conn)
+
+			if err != nil {
+				return
+			}
+
+			switch req.Type {
+			case data.MessageSignInRequest:
+				signIn := data.SignInRequest{}
+				json.Unmarshal(req.Body, &signIn)
+				token = signIn.Token
+
+				send(conn, data.MessageHeader{Type: data.MessageSignInResponse, ReqID: req.ReqID}, SignInResponse{Token: "token"})
+
+			case data.MessageJoinGameRequest:
+				join := data.JoinGameRequest{}
+				json.Unmarshal(req.Body, &join)
+
+				send(conn, data.MessageHeader{Type: data.MessageJoinGameResponse, ReqID: req.ReqID}, JoinGameResponse{GameID: join.GameID, MyID: 2, Seq: 3})
+
+				if index > 0 {
+					rejoined <- rejoin{token, join}
+					continue
+				}
+
+				// the first connection drops after two notifications
+				for seq := 4; seq <= 5; seq++ {
+					send(conn, data.MessageHeader{Type: data.MessageNotifyMoveRecord, GameID: join.GameID, Seq: seq}, MoveRecord{PlayerID: 1, Move: &StopMovingMove{}})
+				}
+
+				return
+			}
+		}
+	})
+
+	opts.Reconnect = true
+	opts.ReconnectDelay = 10 * time.Millisecond
+
+	c := dial(t, opts)
+
+	if _, err := c.SignIn("alice"); err != nil {
+		t.Fatal("sign in failed:", err)
+	}
+
+	if _, err := c.JoinGame("g1"); err != nil {
+		t.Fatal("join game failed:", err)
+	}
+
+	for seq := 4; seq <= 5; seq++ {
+		notification := <-c.Notifications()
+
+		if _, ok := notification.Body.(*MoveRecord); !ok || notification.Seq != seq {
+			t.Errorf("notification %+v, want move record with seq %d", notification, seq)
+		}
+	}
+
+	select {
+	case r := <-rejoined:
+		if r.token != "token" || r.req.GameID != "g1" || r.req.LastSeq != 5 {
+			t.Errorf("rejoined %+v, want token token, game g1 and last seq 5", r)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("the client has not rejoined the game")
+	}
+}
+
+func TestOnError(t *testing.T) {
+	opts := fakeServer(t, func(conn *websocket.Conn, index int) {
+		conn.WriteJSON(data.Envelope{
+			MessageHeader: data.MessageHeader{Type: data.MessageNotifyUserState, GameID: "g1", Seq: 1},
+			Body:          json.RawMessage(`"not a user state"`),
+		})
+	})
+
+	errs := make(chan error, 2)
+
+	opts.OnError = func(err error) {
+		errs <- err
+	}
+
+	c := dial(t, opts)
+
+	for _, want := range []string{"cannot decode notification", "disconnected"} {
+		if err := <-errs; !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("error %v, want %s", err, want)
+		}
+	}
+
+	if _, ok := <-c.Notifications(); ok {
+		t.Error("notifications not closed after disconnection")
+	}
+}
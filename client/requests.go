@@ -0,0 +1,197 @@
+package client
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+)
+
+// SignIn registers a new user with the given name or, if the client has already
+// signed in, authenticates again with its token.
+func (c *Client) SignIn(name string) (*SignInResponse, error) {
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+
+	return c.Authenticate(name, token)
+}
+
+// Authenticate signs in with a token assigned in a previous session.
+// An empty token registers a new user.
+func (c *Client) Authenticate(name, token string) (*SignInResponse, error) {
+	resp := &SignInResponse{}
+
+	err := c.call(data.MessageSignInRequest, "", data.SignInRequest{
+		Name:     name,
+		Token:    token,
+		Language: c.opts.Language,
+	}, resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+
+	if resp.Token != "" {
+		c.token = resp.Token
+	} else {
+		c.token = token
+	}
+
+	c.name = name
+
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// CreateGame creates a new classic table and follows it.
+func (c *Client) CreateGame() (*CreateGameResponse, error) {
+	return c.CreateCustomGame(CreateGameRequest{})
+}
+
+// CreateGameWithSeed creates a new classic table dealt from the seed with the given id, see CreateGameRequest, and follows it.
+func (c *Client) CreateGameWithSeed(seed int64) (*CreateGameResponse, error) {
+	return c.CreateCustomGame(CreateGameRequest{Seed: &seed})
+}
+
+// CreateCustomGame creates a new table with the given variant and seed, and follows it.
+func (c *Client) CreateCustomGame(req CreateGameRequest) (*CreateGameResponse, error) {
+	resp := &CreateGameResponse{}
+
+	if err := c.call(data.MessageCreateGameRequest, "", req, resp); err != nil {
+		return nil, err
+	}
+
+	c.follow(resp.GameID, 0)
+
+	return resp, nil
+}
+
+// JoinGame joins a table and follows it.
+// If the game is already known, eg. after a reconnection, only the missed
+// notifications are sent again.
+func (c *Client) JoinGame(gameID string) (*JoinGameResponse, error) {
+	c.mu.Lock()
+	lastSeq := c.games[gameID]
+	c.mu.Unlock()
+
+	return c.joinGame(gameID, lastSeq)
+}
+
+func (c *Client) joinGame(gameID string, lastSeq int) (*JoinGameResponse, error) {
+	resp := &JoinGameResponse{}
+
+	err := c.call(data.MessageJoinGameRequest, "", data.JoinGameRequest{
+		GameID:  gameID,
+		LastSeq: lastSeq,
+	}, resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSeq == 0 {
+		lastSeq = resp.Seq
+	}
+
+	c.follow(resp.GameID, lastSeq)
+
+	return resp, nil
+}
+
+func (c *Client) follow(gameID string, seq int) {
+	c.mu.Lock()
+
+	if seq >= c.games[gameID] {
+		c.games[gameID] = seq
+	}
+
+	c.mu.Unlock()
+}
+
+// SelectCharacter chooses the character to play in a game.
+func (c *Client) SelectCharacter(gameID string, character Card) error {
+	return c.call(data.MessageSelectCharRequest, gameID, data.SelectCharacterRequest{
+		Character: character,
+	}, nil)
+}
+
+// VoteStart votes to start a game.
+func (c *Client) VoteStart(gameID string, vote bool) error {
+	return c.call(data.MessageVoteStartRequest, gameID, data.VoteStartRequest{
+		Vote: vote,
+	}, nil)
+}
+
+// RollDices rolls the dices.
+func (c *Client) RollDices(gameID string) error {
+	return c.call(data.MessageRollDicesRequest, gameID, nil, nil)
+}
+
+// Move moves the pawn one step in the hallway.
+func (c *Client) Move(gameID string, mapX, mapY int) error {
+	return c.call(data.MessageMoveRequest, gameID, data.MoveRequest{
+		MapX: mapX,
+		MapY: mapY,
+	}, nil)
+}
+
+// StopMoving ends the movement in the hallway before using all the steps.
+func (c *Client) StopMoving(gameID string) error {
+	return c.call(data.MessageStopMovingRequest, gameID, nil, nil)
+}
+
+// EnterRoom moves the pawn in a room, or keeps it in the room it is in.
+func (c *Client) EnterRoom(gameID string, room Card) error {
+	return c.call(data.MessageMoveRequest, gameID, data.MoveRequest{
+		EnterRoom: room,
+	}, nil)
+}
+
+// UsePassage takes the secret passage to room instead of rolling the dices.
+func (c *Client) UsePassage(gameID string, room Card) error {
+	return c.call(data.MessageUsePassageRequest, gameID, data.UsePassageRequest{
+		Room: room,
+	}, nil)
+}
+
+// StayInRoom remains, without rolling the dices, in the room a suggestion moved the pawn in.
+func (c *Client) StayInRoom(gameID string) error {
+	return c.call(data.MessageStayInRoomRequest, gameID, nil, nil)
+}
+
+// QuerySolution suggests a character and a weapon in the room the pawn is in.
+func (c *Client) QuerySolution(gameID string, character, weapon Card) error {
+	return c.call(data.MessageQuerySolutionRequest, gameID, data.QuerySolutionRequest{
+		Character: character,
+		Weapon:    weapon,
+	}, nil)
+}
+
+// Reveal shows a card to the querying player, NoCard if the player has none
+// of the queried cards.
+func (c *Client) Reveal(gameID string, card Card) error {
+	return c.call(data.MessageRevealRequest, gameID, data.RevealRequest{
+		Card: card,
+	}, nil)
+}
+
+// Pass skips investigation or solution declaration.
+func (c *Client) Pass(gameID string) error {
+	return c.call(data.MessagePassRequest, gameID, nil, nil)
+}
+
+// DeclareSolution accuses a character, with a weapon in a room.
+func (c *Client) DeclareSolution(gameID string, declaration Declaration) error {
+	return c.call(data.MessageDeclareSolutionRequest, gameID, data.DeclareSolutionRequest{
+		Declaration: declaration,
+	}, nil)
+}
+
+// SetAutoAnswer lets the server answer for the player when she/he has at most
+// one of the queried cards.
+func (c *Client) SetAutoAnswer(gameID string, autoAnswer bool) error {
+	return c.call(data.MessageSetAutoAnswerRequest, gameID, data.SetAutoAnswerRequest{
+		AutoAnswer: autoAnswer,
+	}, nil)
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// The types below are the messages exchanged with the server and the game values they carry.
+// They are defined by the server internal packages: the aliases let programs outside
+// this module name them.
+
+// Requests and responses.
+type (
+	// MessageType is the type of a message, see data.MessageType.
+	MessageType = data.MessageType
+	// SignInResponse is the response to SignIn and Authenticate.
+	SignInResponse = data.SignInResponse
+	// GameSynopsis is a preview of a game the user has joined, see SignInResponse.
+	GameSynopsis = data.GameSynopsis
+	// GamePlayer is a player of a GameSynopsis.
+	GamePlayer = data.GamePlayer
+	// CreateGameRequest chooses the variant, rules and seed of a new game.
+	CreateGameRequest = data.CreateGameRequest
+	// CreateGameResponse is the response to CreateGame.
+	CreateGameResponse = data.CreateGameResponse
+	// JoinGameResponse is the response to JoinGame.
+	JoinGameResponse = data.JoinGameResponse
+)
+
+// Notifications, see Notification.
+type (
+	// NotifyUserState tells who is at a table, the character she/he chose and whether she/he is online.
+	NotifyUserState = data.NotifyUserState
+	// NotifyGameStarted is sent when a game starts.
+	NotifyGameStarted = data.NotifyGameStarted
+	// NotifyFullState replaces what the client knows of a game.
+	NotifyFullState = data.NotifyFullState
+	// NotifyAnswerOptions lists the cards the player can reveal to answer a query.
+	NotifyAnswerOptions = data.NotifyAnswerOptions
+	// NotifyError is the body of the error a request has been rejected with, see ServerError.
+	NotifyError = data.NotifyError
+)
+
+// Notification types.
+const (
+	MessageNotifyUserState     MessageType = data.MessageNotifyUserState
+	MessageNotifyGameStarted   MessageType = data.MessageNotifyGameStarted
+	MessageNotifyMoveRecord    MessageType = data.MessageNotifyMoveRecord
+	MessageNotifyFullState     MessageType = data.MessageNotifyFullState
+	MessageNotifyAnswerOptions MessageType = data.MessageNotifyAnswerOptions
+)
+
+// Game values.
+type (
+	// Card is a character, a weapon or a room.
+	Card = game.Card
+	// PlayerID identifies a player in a game.
+	PlayerID = game.PlayerID
+	// Declaration is a character, a weapon and a room: a query or an accusation.
+	Declaration = game.Declaration
+	// Rules are the house rules of a game.
+	Rules = game.Rules
+	// AnsweringOrder is the direction the players answer a query in.
+	AnsweringOrder = game.AnsweringOrder
+	// State is the state of a game.
+	State = game.State
+	// StateUpdate is the state of a game, or what a move changed of it.
+	StateUpdate = game.StateUpdate
+	// PawnPosition is a cell of the board or a room.
+	PawnPosition = game.PawnPosition
+	// PlayerPosition is where the pawn of a player is.
+	PlayerPosition = game.PlayerPosition
+	// WeaponPosition is the room a weapon token is in.
+	WeaponPosition = game.WeaponPosition
+	// PlayerDeclaration is the accusation of a player.
+	PlayerDeclaration = game.PlayerDeclaration
+	// Seed is the seed a game has been drawn from, revealed when it ends.
+	Seed = game.Seed
+	// PlayerDeck is the deck dealt to a player, revealed when the game ends.
+	PlayerDeck = game.PlayerDeck
+	// Error is the code of a ServerError.
+	Error = game.Error
+	// ErrorDetails help to recover from a ServerError.
+	ErrorDetails = game.ErrorDetails
+)
+
+// Moves, the body of a MessageNotifyMoveRecord notification.
+type (
+	// MoveRecord is a move and its effects on the game.
+	MoveRecord = game.MoveRecord
+	// Move is one of the *...Move types below.
+	Move = game.Move
+	// MoveType tells the type of a Move.
+	MoveType = game.MoveType
+
+	StartMove              = game.StartMove
+	RollDicesMove          = game.RollDicesMove
+	MovingInTheHallwayMove = game.MovingInTheHallwayMove
+	EnterRoomMove          = game.EnterRoomMove
+	QuerySolutionMove      = game.QuerySolutionMove
+	PassMove               = game.PassMove
+	RevealCardMove         = game.RevealCardMove
+	NoCardToRevealMove     = game.NoCardToRevealMove
+	DeclareSolutionMove    = game.DeclareSolutionMove
+	UsePassageMove         = game.UsePassageMove
+	StayInRoomMove         = game.StayInRoomMove
+	StopMovingMove         = game.StopMovingMove
+)
+
+// Cards of the classic variant.
+const (
+	NoCard = game.NoCard
+
+	Candlestick = game.Candlestick
+	Knife       = game.Knife
+	LeadPipe    = game.LeadPipe
+	Revolver    = game.Revolver
+	Rope        = game.Rope
+	Wrenck      = game.Wrenck
+
+	Kitchen      = game.Kitchen
+	Ballroom     = game.Ballroom
+	Conservatory = game.Conservatory
+	DiningRoom   = game.DiningRoom
+	BilliardRoom = game.BilliardRoom
+	Library      = game.Library
+	Lounge       = game.Lounge
+	Hall         = game.Hall
+	Study        = game.Study
+
+	MissScarlett = game.MissScarlett
+	RevGreen     = game.RevGreen
+	ColMustard   = game.ColMustard
+	ProfPlum     = game.ProfPlum
+	MrsPeacock   = game.MrsPeacock
+	MrsWhite     = game.MrsWhite
+)
+
+// ClassicVariant is the name of the classic variant, see CreateGameRequest.
+const ClassicVariant = game.ClassicVariant
+
+// Answering orders, see Rules.
+const (
+	Clockwise        = game.Clockwise
+	Counterclockwise = game.Counterclockwise
+)
+
+// Game states, see StateUpdate.
+const (
+	GameStateStarting    = game.GameStateStarting
+	GameStateNewTurn     = game.GameStateNewTurn
+	GameStateCard        = game.GameStateCard
+	GameStateMove        = game.GameStateMove
+	GameStateQuery       = game.GameStateQuery
+	GameStateTrySolution = game.GameStateTrySolution
+	GameEnded            = game.GameEnded
+)
+
+// Move types, see Move.
+const (
+	Start              = game.Start
+	RollDices          = game.RollDices
+	MovingInTheHallway = game.MovingInTheHallway
+	EnterRoom          = game.EnterRoom
+	QuerySolution      = game.QuerySolution
+	NoCardToReveal     = game.NoCardToReveal
+	RevealCard         = game.RevealCard
+	DeclareSolution    = game.DeclareSolution
+	Pass               = game.Pass
+	UsePassage         = game.UsePassage
+	StayInRoom         = game.StayInRoom
+	StopMoving         = game.StopMoving
+)
+
+// Error codes, see ServerError.
+const (
+	UnknownRequest         = game.UnknownRequest
+	BadRequest             = game.BadRequest
+	NotSignedIn            = game.NotSignedIn
+	CannotJoinRunningGame  = game.CannotJoinRunningGame
+	TableIsFull            = game.TableIsFull
+	TokenMismatch          = game.TokenMismatch
+	UnknownToken           = game.UnknownToken
+	TooManyGames           = game.TooManyGames
+	UnknownGame            = game.UnknownGame
+	AlreadyPlaying         = game.AlreadyPlaying
+	AlreadySelected        = game.AlreadySelected
+	NotACharacter          = game.NotACharacter
+	NotAWeapon             = game.NotAWeapon
+	NotARoom               = game.NotARoom
+	UnknownVariant         = game.UnknownVariant
+	BadRules               = game.BadRules
+	NotPlaying             = game.NotPlaying
+	GameAlreadyStarted     = game.GameAlreadyStarted
+	CharacterNotSelected   = game.CharacterNotSelected
+	NotEnoughPlayers       = game.NotEnoughPlayers
+	NotYourTurn            = game.NotYourTurn
+	IllegalState           = game.IllegalState
+	IllegalMove            = game.IllegalMove
+	AlreadyVisited         = game.AlreadyVisited
+	DoorBlocked            = game.DoorBlocked
+	NotYourCard            = game.NotYourCard
+	CardNotQueried         = game.CardNotQueried
+	MustShowACard          = game.MustShowACard
+	NotInARoom             = game.NotInARoom
+	NotPulledBySuggestion  = game.NotPulledBySuggestion
+	RepeatedRoomSuggestion = game.RepeatedRoomSuggestion
+	TooManyRequests        = game.TooManyRequests
+	InternalError          = game.InternalError
+)
+
+// ParseCard returns the card with the given name, eg. "knife" or "miss_scarlett".
+func ParseCard(name string) (Card, bool) {
+	return game.ParseCard(name)
+}
+
+// CommitSolution returns the solution commitment of NotifyGameStarted given what is revealed
+// when the game ends: comparing them proves the solution has not changed during the game.
+func CommitSolution(seed Seed, nonce string, solution Declaration) string {
+	return game.CommitSolution(seed, nonce, solution)
+}
+
+// CommitDeal returns the deal commitment of NotifyGameStarted given what is revealed
+// when the game ends, see CommitSolution.
+func CommitDeal(seed Seed, nonce string, decks []PlayerDeck) string {
+	return game.CommitDeal(seed, nonce, decks)
+}
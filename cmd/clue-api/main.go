@@ -1,6 +1,7 @@
 package main
 
 import (
+	"expvar"
 	"flag"
 	"log"
 	"math/rand"
@@ -8,16 +9,24 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/events"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
 	"github.com/makeroo/my_clue_be/internal/platform/web/handlers"
 )
 
 func main() {
 	addr := flag.String("addr", "127.0.0.1:8080", "http service address")
+	rate := flag.Float64("rate", 20, "requests per second allowed to a websocket on average")
+	burst := flag.Int("burst", 40, "requests allowed to a websocket in a burst")
+	queue := flag.Int("queue", 1024, "messages a websocket can have waiting to be sent")
+	overflow := flag.String("overflow", string(web.OverflowResync), "what to do with the clients too slow to keep up: resync or disconnect")
+	idle := flag.Duration("idle", 0, "how long a game nobody follows is kept, even if running; 0 keeps it until it ends")
 
 	flag.Parse()
 
 	upgrader := websocket.Upgrader{
+		Subprotocols: data.Protocols,
 		CheckOrigin: func(r *http.Request) bool {
 			// TODO: implement check origin
 			// see https://github.com/gorilla/websocket/issues/367
@@ -31,19 +40,40 @@ func main() {
 
 	server := web.New(&upgrader, seededRand)
 
-	server.RegisterHandler(&handlers.SignInHandler{})
-	server.RegisterHandler(&handlers.CreateGameHandler{})
-	server.RegisterHandler(&handlers.JoinGameHandler{})
-	server.RegisterHandler(&handlers.SelectCharHandler{})
-	server.RegisterHandler(&handlers.VoteStartHandler{})
-	server.RegisterHandler(&handlers.RollDicesHandler{})
-	server.RegisterHandler(&handlers.MoveHandler{})
-	server.RegisterHandler(&handlers.PassHandler{})
-	server.RegisterHandler(&handlers.QuerySolutionHandler{})
-	server.RegisterHandler(&handlers.RevealHandler{})
-	server.RegisterHandler(&handlers.DeclareSolutionHandler{})
-
-	server.Run()
+	switch policy := web.OverflowPolicy(*overflow); policy {
+	case web.OverflowResync, web.OverflowDisconnect:
+		server.SetOutboundQueue(*queue, policy)
+	default:
+		log.Fatalf("unknown overflow policy %s", policy)
+	}
+
+	server.SetGameIdleTimeout(*idle)
+
+	for _, handler := range handlers.All() {
+		server.RegisterHandler(handler)
+	}
+
+	// metrics are published at /debug/vars
+	expvar.Publish("outbound_queues", expvar.Func(func() interface{} {
+		return server.QueueDepths()
+	}))
+
+	eventCounts := expvar.NewMap("events")
+
+	server.Events().Subscribe(func(e events.Event) {
+		eventCounts.Add(string(e.Kind()), 1)
+	}, events.DeliverLatest)
+
+	expvar.Publish("events_dropped", expvar.Func(func() interface{} {
+		return server.Events().Dropped()
+	}))
+
+	server.Use(
+		web.Recover,
+		web.Logger,
+		web.Metrics(expvar.NewMap("requests"), expvar.NewMap("requests_ns"), expvar.NewMap("errors")),
+		web.RateLimit(*rate, *burst),
+	)
 
 	http.Handle("/clue/ws", logRequest(server))
 
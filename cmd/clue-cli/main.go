@@ -0,0 +1,512 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/makeroo/my_clue_be/client"
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+const help = `commands:
+  create [VARIANT] [SEED] [RULE...]
+                            create a new game of VARIANT, classic is the only one,
+                            dealt from SEED if given, with house RULEs:
+                            counterclockwise, skip_eliminated_players,
+                            face_up_leftovers, no_repeated_room_suggestion,
+                            public_reveals, auto_answer
+  join GAME                 join a game
+  games                     list followed games
+  use GAME                  switch to another followed game
+  char CHARACTER            select your character, eg. char plum
+  start                     vote to start the game
+  roll                      roll the dices
+  passage ROOM              take the secret passage to ROOM instead of rolling
+  move X Y                  move one step in the hallway
+  stop                      end your movement in the hallway
+  enter ROOM                enter a room from its door, eg. enter kitchen
+  stay                      remain in the room you are in, before rolling
+                            if a suggestion moved you there
+  suggest CHARACTER WEAPON  query the solution in the room you are in
+  reveal CARD|none          show a card to the querying player
+  auto on|off               let the server answer for you when you have
+                            at most one of the suggested cards
+  pass                      skip suggestion or accusation
+  accuse CHARACTER WEAPON ROOM
+  board                     show the board
+  deck                      show your cards
+  state                     show the game state
+  help                      show this help
+  quit`
+
+// cli is an interactive session: it follows many games, one of them has the focus.
+type cli struct {
+	client *client.Client
+
+	mu     sync.Mutex
+	tables map[string]*table
+	focus  string
+}
+
+func main() {
+	url := flag.String("url", "ws://127.0.0.1:8080/clue/ws", "clue server websocket url")
+	name := flag.String("name", os.Getenv("USER"), "player name")
+	token := flag.String("token", "", "token of a previous session")
+	lang := flag.String("lang", os.Getenv("LANG"), "language of the error messages, eg. it or en")
+
+	flag.Parse()
+
+	c, err := client.Dial(client.Options{
+		URL:       *url,
+		Reconnect: true,
+		Language:  *lang,
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer c.Close()
+
+	resp, err := c.Authenticate(*name, *token)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("signed in as %s, token %s (use -token to sign in again)\n", *name, c.Token())
+
+	s := &cli{
+		client: c,
+		tables: make(map[string]*table),
+	}
+
+	for _, synopsis := range resp.RunningGames {
+		fmt.Printf("running game %s: %s\n", synopsis.ID, synopsis.Game.State)
+	}
+
+	go s.notifications()
+
+	fmt.Println(`type "help" for the list of commands`)
+
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for fmt.Print("> "); scanner.Scan(); fmt.Print("> ") {
+		args := strings.Fields(strings.ToLower(scanner.Text()))
+
+		if len(args) == 0 {
+			continue
+		}
+
+		if args[0] == "quit" || args[0] == "exit" {
+			return
+		}
+
+		if err := s.execute(args[0], args[1:]); err != nil {
+			fmt.Println("error:", err)
+
+			printErrorDetails(err)
+		}
+	}
+}
+
+// printErrorDetails prints the hints the server sent along with the error, if any.
+func printErrorDetails(err error) {
+	var serverError *client.ServerError
+
+	if !errors.As(err, &serverError) || serverError.Details == nil {
+		return
+	}
+
+	if len(serverError.Details.States) > 0 {
+		states := make([]string, len(serverError.Details.States))
+
+		for i, state := range serverError.Details.States {
+			states[i] = state.String()
+		}
+
+		fmt.Println("  valid in:", strings.Join(states, ", "))
+	}
+
+	if len(serverError.Details.Cards) > 0 {
+		fmt.Println("  valid cards:", cardList(serverError.Details.Cards))
+	}
+}
+
+func (s *cli) notifications() {
+	for n := range s.client.Notifications() {
+		s.mu.Lock()
+
+		t := s.tables[n.GameID]
+
+		if t == nil {
+			s.mu.Unlock()
+			continue
+		}
+
+		wasMyTurn := t.myTurn()
+		description := t.apply(n.Type, n.Body)
+
+		if description != "" {
+			fmt.Printf("\n[%s] %s\n", t.id, description)
+		}
+
+		if n.GameID != s.focus && !wasMyTurn && t.myTurn() {
+			fmt.Printf("[%s] it's your turn (use %s)\n", t.id, t.id)
+		}
+
+		s.mu.Unlock()
+	}
+
+	fmt.Println("\ndisconnected")
+	os.Exit(1)
+}
+
+// current returns the focused game.
+func (s *cli) current() (*table, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[s.focus]
+
+	if t == nil {
+		return nil, fmt.Errorf("create or join a game first")
+	}
+
+	return t, nil
+}
+
+func (s *cli) execute(command string, args []string) error {
+	switch command {
+	case "help":
+		fmt.Println(help)
+		return nil
+
+	case "create":
+		req := data.CreateGameRequest{}
+
+		for _, arg := range args {
+			if seed, err := strconv.ParseInt(arg, 10, 64); err == nil && req.Seed == nil {
+				req.Seed = &seed
+			} else if _, ok := game.VariantByName(arg); ok && req.Variant == "" {
+				req.Variant = arg
+			} else if !req.Rules.Set(arg) {
+				return fmt.Errorf("usage: create [VARIANT] [SEED] [RULE...]")
+			}
+		}
+
+		resp, err := s.client.CreateCustomGame(req)
+
+		if err != nil {
+			return err
+		}
+
+		t := newTable(resp.GameID, resp.MyID)
+		t.setVariant(resp.Variant)
+		t.rules = resp.Rules
+		t.players[resp.MyID] = data.NotifyUserState{
+			ID:     resp.MyID,
+			Online: true,
+		}
+
+		s.follow(t)
+
+		fmt.Printf("created %s game %s%s, tell your friends to join it\n", t.variant.Name, resp.GameID, t.rulesDescription())
+
+		return nil
+
+	case "join":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: join GAME")
+		}
+
+		gameID := strings.ToUpper(args[0])
+		t := newTable(gameID, 0)
+
+		// follow before joining: notifications may arrive before the response
+		s.follow(t)
+
+		resp, err := s.client.JoinGame(gameID)
+
+		if err != nil {
+			s.mu.Lock()
+			delete(s.tables, gameID)
+			s.mu.Unlock()
+
+			return err
+		}
+
+		s.mu.Lock()
+		t.myID = resp.MyID
+		t.setVariant(resp.Variant)
+		t.rules = resp.Rules
+		for _, p := range resp.Players {
+			t.players[p.ID] = p
+		}
+		s.mu.Unlock()
+
+		fmt.Printf("joined %s game %s%s\n", t.variant.Name, gameID, t.rulesDescription())
+
+		return nil
+
+	case "games":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		ids := make([]string, 0, len(s.tables))
+		for id := range s.tables {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			mark := " "
+			if id == s.focus {
+				mark = "*"
+			}
+			turn := ""
+			if s.tables[id].myTurn() {
+				turn = " (your turn)"
+			}
+			fmt.Printf("%s %s %s%s\n", mark, id, s.tables[id].state.State, turn)
+		}
+
+		return nil
+
+	case "use":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: use GAME")
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		gameID := strings.ToUpper(args[0])
+
+		if s.tables[gameID] == nil {
+			return fmt.Errorf("unknown game %s", gameID)
+		}
+
+		s.focus = gameID
+
+		fmt.Println(s.tables[gameID].status())
+
+		return nil
+	}
+
+	t, err := s.current()
+
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "char":
+		character, err := parseCard(args, 0, t.variant.Characters)
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.SelectCharacter(t.id, character)
+
+	case "start":
+		return s.client.VoteStart(t.id, true)
+
+	case "roll":
+		return s.client.RollDices(t.id)
+
+	case "move":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: move X Y")
+		}
+
+		x, err := strconv.Atoi(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		y, err := strconv.Atoi(args[1])
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.Move(t.id, x, y)
+
+	case "stop":
+		return s.client.StopMoving(t.id)
+
+	case "enter":
+		room, err := parseCard(args, 0, t.variant.Rooms)
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.EnterRoom(t.id, room)
+
+	case "passage":
+		room, err := parseCard(args, 0, t.variant.Rooms)
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.UsePassage(t.id, room)
+
+	case "stay":
+		s.mu.Lock()
+		room := t.positions[t.myID].Room
+		state := t.state.State
+		s.mu.Unlock()
+
+		if !game.IsRoom(room) {
+			return fmt.Errorf("you are not in a room")
+		}
+
+		if state == game.GameStateNewTurn {
+			return s.client.StayInRoom(t.id)
+		}
+
+		return s.client.EnterRoom(t.id, room)
+
+	case "suggest":
+		character, err := parseCard(args, 0, t.variant.Characters)
+
+		if err != nil {
+			return err
+		}
+
+		weapon, err := parseCard(args, 1, t.variant.Weapons)
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.QuerySolution(t.id, character, weapon)
+
+	case "reveal":
+		if len(args) == 1 && args[0] == "none" {
+			return s.client.Reveal(t.id, game.NoCard)
+		}
+
+		card, err := parseCard(args, 0, t.variant.Cards())
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.Reveal(t.id, card)
+
+	case "pass":
+		return s.client.Pass(t.id)
+
+	case "auto":
+		if len(args) != 1 || args[0] != "on" && args[0] != "off" {
+			return fmt.Errorf("usage: auto on|off")
+		}
+
+		return s.client.SetAutoAnswer(t.id, args[0] == "on")
+
+	case "accuse":
+		character, err := parseCard(args, 0, t.variant.Characters)
+
+		if err != nil {
+			return err
+		}
+
+		weapon, err := parseCard(args, 1, t.variant.Weapons)
+
+		if err != nil {
+			return err
+		}
+
+		room, err := parseCard(args, 2, t.variant.Rooms)
+
+		if err != nil {
+			return err
+		}
+
+		return s.client.DeclareSolution(t.id, game.Declaration{
+			Character: character,
+			Weapon:    weapon,
+			Room:      room,
+		})
+
+	case "board":
+		s.mu.Lock()
+		fmt.Print(t.renderBoard())
+		s.mu.Unlock()
+
+		return nil
+
+	case "deck":
+		s.mu.Lock()
+		fmt.Println(cardList(t.deck))
+		if len(t.faceUp) > 0 {
+			fmt.Println("face up: " + cardList(t.faceUp))
+		}
+		s.mu.Unlock()
+
+		return nil
+
+	case "state":
+		s.mu.Lock()
+		fmt.Println(t.status())
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %s, try help", command)
+}
+
+// follow adds a table to the followed ones and gives it the focus.
+func (s *cli) follow(t *table) {
+	s.mu.Lock()
+	s.tables[t.id] = t
+	s.focus = t.id
+	s.mu.Unlock()
+}
+
+// parseCard parses args[i] as a card of the given kind. Partial names are
+// accepted if not ambiguous, eg. plum for prof_plum.
+func parseCard(args []string, i int, cards []game.Card) (game.Card, error) {
+	if i >= len(args) {
+		return game.NoCard, fmt.Errorf("missing card, try help")
+	}
+
+	name := args[i]
+
+	var found []game.Card
+
+	for _, card := range cards {
+		if card.String() == name {
+			return card, nil
+		}
+
+		if strings.Contains(card.String(), name) {
+			found = append(found, card)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return game.NoCard, fmt.Errorf("unknown card %s", name)
+	case 1:
+		return found[0], nil
+	default:
+		return game.NoCard, fmt.Errorf("ambiguous card %s: %s", name, cardList(found))
+	}
+}
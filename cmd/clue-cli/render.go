@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// doorMarks are the symbols drawn in front of room doors.
+var doorMarks = map[game.Card]byte{
+	game.Kitchen:      'k',
+	game.Ballroom:     'b',
+	game.Conservatory: 'c',
+	game.DiningRoom:   'd',
+	game.BilliardRoom: 'i',
+	game.Library:      'l',
+	game.Lounge:       'o',
+	game.Hall:         'h',
+	game.Study:        's',
+
+	game.CarriageHouse: 'a',
+	game.TrophyRoom:    't',
+	game.DrawingRoom:   'r',
+	game.Gazebo:        'g',
+	game.Courtyard:     'y',
+	game.Fountain:      'f',
+	game.Studio:        'u',
+}
+
+// renderBoard draws the board: walls are '#', hallways '.', doors lower case
+// letters and pawns their player id.
+func (t *table) renderBoard() string {
+	var b strings.Builder
+
+	board := t.variant.Board
+
+	b.WriteString("   ")
+	for x := 0; x < board.Width(); x++ {
+		fmt.Fprintf(&b, "%d", x%10)
+	}
+	b.WriteString("\n")
+
+	for y := 0; y < board.Height(); y++ {
+		fmt.Fprintf(&b, "%2d ", y)
+
+		for x := 0; x < board.Width(); x++ {
+			b.WriteByte(t.cellMark(x, y))
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString("doors:")
+
+	for _, room := range t.variant.Rooms {
+		fmt.Fprintf(&b, " %c=%s", doorMarks[room], room)
+	}
+
+	b.WriteString("\n")
+
+	if len(t.weapons) > 0 {
+		b.WriteString("weapons:")
+
+		for _, weapon := range t.variant.Weapons {
+			if room, ok := t.weapons[weapon]; ok {
+				fmt.Fprintf(&b, " %s=%s", weapon, room)
+			}
+		}
+
+		b.WriteString("\n")
+	}
+
+	for _, id := range t.playerIDs() {
+		pos, ok := t.positions[id]
+
+		if !ok {
+			continue
+		}
+
+		where := fmt.Sprintf("%d %d", pos.MapX, pos.MapY)
+
+		if pos.InRoom() {
+			where = pos.Room.String()
+		}
+
+		fmt.Fprintf(&b, "%d: %s (%s) %s\n", id, t.playerName(id), t.players[id].Character, where)
+	}
+
+	return b.String()
+}
+
+func (t *table) cellMark(x, y int) byte {
+	for id, pos := range t.positions {
+		if !pos.InRoom() && pos.MapX == x && pos.MapY == y {
+			return byte('0' + int(id)%10)
+		}
+	}
+
+	cell := t.variant.Board.Cell(x, y)
+
+	switch {
+	case cell < 0:
+		return '#'
+	case cell == 0:
+		return '.'
+	default:
+		return doorMarks[game.Card(cell)]
+	}
+}
+
+func (t *table) playerIDs() []game.PlayerID {
+	if len(t.order) > 0 {
+		return t.order
+	}
+
+	ids := make([]game.PlayerID, 0, len(t.players))
+
+	for id := range t.players {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
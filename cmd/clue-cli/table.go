@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// table is what the cli knows of a followed game, rebuilt from notifications.
+type table struct {
+	id      string
+	variant *game.Variant
+	rules   game.Rules
+	myID    game.PlayerID
+	players map[game.PlayerID]data.NotifyUserState
+	order   []game.PlayerID
+	deck    []game.Card
+	faceUp  []game.Card
+	state   game.StateUpdate
+	// commitments received when the game started, verified when it ends
+	solutionCommitment string
+	dealCommitment     string
+	// positions are indexed by player id
+	positions map[game.PlayerID]game.PawnPosition
+	// pulled are the players moved by a suggestion since their last turn
+	pulled map[game.PlayerID]bool
+	// weapons maps each weapon token to the room it is in
+	weapons map[game.Card]game.Card
+}
+
+func newTable(id string, myID game.PlayerID) *table {
+	return &table{
+		id:        id,
+		variant:   game.Classic,
+		myID:      myID,
+		players:   make(map[game.PlayerID]data.NotifyUserState),
+		positions: make(map[game.PlayerID]game.PawnPosition),
+		pulled:    make(map[game.PlayerID]bool),
+		weapons:   make(map[game.Card]game.Card),
+	}
+}
+
+// setVariant sets the game variant, keeping the classic one if the name is unknown.
+func (t *table) setVariant(name string) {
+	if variant, ok := game.VariantByName(name); ok {
+		t.variant = variant
+	}
+}
+
+// rulesDescription returns the house rules of the game, if any, as a suffix
+// for create and join messages.
+func (t *table) rulesDescription() string {
+	if rules := t.rules.String(); rules != "" {
+		return " (" + rules + ")"
+	}
+
+	return ""
+}
+
+func (t *table) playerName(id game.PlayerID) string {
+	if id == t.myID {
+		return "you"
+	}
+
+	if p, ok := t.players[id]; ok && p.Name != "" {
+		return p.Name
+	}
+
+	return fmt.Sprintf("player %d", id)
+}
+
+// myTurn returns true if the game is waiting for an action of this player.
+func (t *table) myTurn() bool {
+	switch t.state.State {
+	case game.GameStateStarting, game.GameEnded:
+		return false
+	case game.GameStateQuery:
+		if t.state.AnsweringPlayer != 0 {
+			return t.state.AnsweringPlayer == t.myID
+		}
+	}
+
+	return t.state.CurrentPlayer == t.myID
+}
+
+// apply updates the table with a notification and returns its description.
+func (t *table) apply(messageType data.MessageType, body interface{}) string {
+	switch b := body.(type) {
+	case *data.NotifyUserState:
+		old, known := t.players[b.ID]
+		t.players[b.ID] = *b
+
+		switch {
+		case !known:
+			return fmt.Sprintf("%s joined", t.playerName(b.ID))
+		case old.Online != b.Online && b.Online:
+			return fmt.Sprintf("%s is back online", t.playerName(b.ID))
+		case old.Online != b.Online:
+			return fmt.Sprintf("%s went offline", t.playerName(b.ID))
+		case old.Character != b.Character:
+			return fmt.Sprintf("%s selected %s", t.playerName(b.ID), b.Character)
+		default:
+			return ""
+		}
+
+	case *data.NotifyGameStarted:
+		t.deck = b.Deck
+		t.faceUp = b.FaceUp
+		t.order = b.PlayersOrder
+		t.solutionCommitment = b.SolutionCommitment
+		t.dealCommitment = b.DealCommitment
+
+		if len(t.faceUp) > 0 {
+			return "game started, your deck: " + cardList(t.deck) + ", face up: " + cardList(t.faceUp)
+		}
+
+		return "game started, your deck: " + cardList(t.deck)
+
+	case *data.NotifyFullState:
+		t.deck = b.Deck
+		t.faceUp = b.FaceUp
+		t.order = b.PlayersOrder
+		t.solutionCommitment = b.SolutionCommitment
+		t.dealCommitment = b.DealCommitment
+
+		for _, p := range b.Players {
+			t.players[p.ID] = p
+		}
+
+		t.state = game.StateUpdate{}
+		t.pulled = make(map[game.PlayerID]bool)
+		t.applyDelta(b.Game)
+
+		return "game state reloaded"
+
+	case *data.NotifyAnswerOptions:
+		if len(b.Cards) == 0 {
+			return "you have none of the suggested cards: reveal none"
+		}
+
+		return "you can show: " + cardList(b.Cards)
+
+	case *game.MoveRecord:
+		switch b.Move.(type) {
+		case *game.RollDicesMove, *game.UsePassageMove, *game.StayInRoomMove:
+			delete(t.pulled, b.PlayerID)
+		}
+
+		t.applyDelta(b.StateDelta)
+
+		if b.StateDelta.State == game.GameEnded {
+			return t.describe(b) + "\n" + t.verify()
+		}
+
+		return t.describe(b)
+	}
+
+	return ""
+}
+
+func (t *table) applyDelta(delta game.StateUpdate) {
+	t.state.State = delta.State
+
+	if delta.CurrentPlayer != 0 {
+		t.state.CurrentPlayer = delta.CurrentPlayer
+	}
+
+	if delta.Dice1 != 0 {
+		t.state.Dice1 = delta.Dice1
+		t.state.Dice2 = delta.Dice2
+	}
+
+	t.state.RemainingSteps = delta.RemainingSteps
+
+	if len(delta.FaceUp) > 0 {
+		t.faceUp = delta.FaceUp
+	}
+
+	for _, p := range delta.Positions {
+		t.positions[p.PlayerID] = p.PawnPosition
+	}
+
+	for _, w := range delta.Weapons {
+		t.weapons[w.Weapon] = w.Room
+	}
+
+	for _, id := range delta.PulledBySuggestion {
+		t.pulled[id] = true
+	}
+
+	switch delta.State {
+	case game.GameStateNewTurn:
+		t.state.Query = nil
+		t.state.AnsweringPlayer = 0
+		t.state.Revealed = false
+		t.state.RevealedCard = game.NoCard
+
+	case game.GameStateQuery, game.GameStateTrySolution:
+		if delta.Query != nil {
+			t.state.Query = delta.Query
+		}
+
+		t.state.AnsweringPlayer = delta.AnsweringPlayer
+		t.state.Revealed = delta.Revealed
+		t.state.RevealedCard = delta.RevealedCard
+
+	case game.GameEnded:
+		t.state.Solution = delta.Solution
+		t.state.Seed = delta.Seed
+		t.state.Nonce = delta.Nonce
+		t.state.Decks = delta.Decks
+	}
+}
+
+func (t *table) describe(record *game.MoveRecord) string {
+	who := t.playerName(record.PlayerID)
+
+	switch move := record.Move.(type) {
+	case *game.StartMove:
+		return fmt.Sprintf("%s starts", t.playerName(record.StateDelta.CurrentPlayer))
+	case *game.RollDicesMove:
+		return fmt.Sprintf("%s rolled %d+%d", who, move.Dice1, move.Dice2)
+	case *game.MovingInTheHallwayMove:
+		return fmt.Sprintf("%s moved to %d %d, %d steps left", who, move.MapX, move.MapY, record.StateDelta.RemainingSteps)
+	case *game.EnterRoomMove:
+		return fmt.Sprintf("%s is in the %s", who, move.Room)
+	case *game.UsePassageMove:
+		return fmt.Sprintf("%s takes the secret passage to the %s", who, move.Room)
+	case *game.StopMovingMove:
+		return fmt.Sprintf("%s stops in the hallway", who)
+	case *game.StayInRoomMove:
+		return fmt.Sprintf("%s stays in the %s without rolling", who, move.Room)
+	case *game.QuerySolutionMove:
+		room := game.NoCard
+		if t.state.Query != nil {
+			room = t.state.Query.Room
+		}
+		return fmt.Sprintf("%s suggests %s with the %s in the %s, %s has to answer", who, move.Character, move.Weapon, room, t.playerName(record.StateDelta.AnsweringPlayer))
+	case *game.NoCardToRevealMove:
+		return fmt.Sprintf("%s has no card to show", who)
+	case *game.RevealCardMove:
+		if move.Card == game.NoCard {
+			return fmt.Sprintf("%s showed a card", who)
+		}
+		return fmt.Sprintf("%s showed %s", who, move.Card)
+	case *game.DeclareSolutionMove:
+		if record.StateDelta.State == game.GameEnded {
+			return fmt.Sprintf("game ended: %s with the %s in the %s", move.Character, move.Weapon, move.Room)
+		}
+		return fmt.Sprintf("%s accused %s with the %s in the %s: wrong!", who, move.Character, move.Weapon, move.Room)
+	case *game.PassMove:
+		return fmt.Sprintf("%s passes", who)
+	}
+
+	return fmt.Sprintf("%s: %s", who, record.Move.MoveType())
+}
+
+// verify checks the revealed solution and deal against the commitments.
+func (t *table) verify() string {
+	if t.solutionCommitment == "" || t.state.Solution == nil || t.state.Seed == nil {
+		return "no commitment to verify"
+	}
+
+	if game.CommitSolution(*t.state.Seed, t.state.Nonce, *t.state.Solution) != t.solutionCommitment {
+		return "WARNING: the solution doesn't match the commitment received when the game started"
+	}
+
+	if game.CommitDeal(*t.state.Seed, t.state.Nonce, t.state.Decks) != t.dealCommitment {
+		return "WARNING: the deal doesn't match the commitment received when the game started"
+	}
+
+	// face up cards are not committed: check that they complete the deal
+	dealt := map[game.Card]int{
+		t.state.Solution.Character: 1,
+		t.state.Solution.Weapon:    1,
+		t.state.Solution.Room:      1,
+	}
+
+	for _, card := range t.faceUp {
+		dealt[card]++
+	}
+
+	for _, d := range t.state.Decks {
+		for _, card := range d.Deck {
+			dealt[card]++
+		}
+	}
+
+	for _, card := range t.variant.Cards() {
+		if dealt[card] != 1 {
+			return fmt.Sprintf("WARNING: card %s has been dealt %d times", card, dealt[card])
+		}
+	}
+
+	return "solution and deal match the commitments received when the game started"
+}
+
+// status describes the game state.
+func (t *table) status() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "game %s: %s", t.id, t.state.State)
+
+	switch t.state.State {
+	case game.GameStateStarting:
+		for _, p := range t.players {
+			fmt.Fprintf(&b, "\n  %s (%s)", t.playerName(p.ID), p.Character)
+		}
+
+	case game.GameStateMove:
+		fmt.Fprintf(&b, ", %s has %d steps left", t.playerName(t.state.CurrentPlayer), t.state.RemainingSteps)
+
+	case game.GameStateQuery, game.GameStateTrySolution:
+		fmt.Fprintf(&b, ", current player: %s", t.playerName(t.state.CurrentPlayer))
+
+		if t.state.Query != nil && t.state.Query.Character != game.NoCard {
+			q := t.state.Query
+			fmt.Fprintf(&b, "\n  suggestion: %s with the %s in the %s", q.Character, q.Weapon, q.Room)
+		}
+
+		if t.state.AnsweringPlayer != 0 {
+			fmt.Fprintf(&b, "\n  waiting for %s to answer", t.playerName(t.state.AnsweringPlayer))
+		}
+
+		if t.state.RevealedCard != game.NoCard {
+			fmt.Fprintf(&b, "\n  revealed: %s", t.state.RevealedCard)
+		}
+
+	case game.GameEnded:
+		if s := t.state.Solution; s != nil {
+			fmt.Fprintf(&b, ", solution: %s with the %s in the %s", s.Character, s.Weapon, s.Room)
+		}
+
+		if t.state.Seed != nil {
+			fmt.Fprintf(&b, "\n  seed: %s", *t.state.Seed)
+		}
+
+	default:
+		fmt.Fprintf(&b, ", current player: %s", t.playerName(t.state.CurrentPlayer))
+	}
+
+	if t.myTurn() {
+		b.WriteString("\n  it's your turn")
+
+		if t.state.State == game.GameStateNewTurn && t.pulled[t.myID] {
+			b.WriteString(", a suggestion moved you: you may stay and suggest without rolling")
+		}
+	}
+
+	return b.String()
+}
+
+func cardList(cards []game.Card) string {
+	names := make([]string, len(cards))
+
+	for i, c := range cards {
+		names[i] = c.String()
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/makeroo/my_clue_be/internal/platform/schema"
+	"github.com/makeroo/my_clue_be/internal/platform/web/handlers"
+)
+
+func main() {
+	format := flag.String("format", "json", "output format: json (JSON Schema) or ts (TypeScript definitions)")
+	out := flag.String("out", "", "output file, stdout if not specified")
+
+	flag.Parse()
+
+	protocol := schema.Build(handlers.All())
+
+	var w io.Writer = os.Stdout
+
+	if *out != "" {
+		f, err := os.Create(*out)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer f.Close()
+
+		w = f
+	}
+
+	var err error
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(schema.JSONSchema(protocol))
+
+	case "ts":
+		err = schema.WriteTypeScript(w, protocol)
+
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
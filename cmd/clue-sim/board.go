@@ -0,0 +1,145 @@
+package main
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+type cell struct {
+	x, y int
+}
+
+var directions = []cell{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// walkable returns true if a pawn can step on the cell.
+func walkable(g *game.Game, c cell) bool {
+	return g.IsValidPosition(c.x, c.y) && g.Variant().Board.Cell(c.x, c.y) >= 0 && g.IsOccupied(c.x, c.y) == nil &&
+		!visited(g, game.PositionAt(c.x, c.y))
+}
+
+// visited returns true if the current player has been in the position since she/he rolled the dices.
+func visited(g *game.Game, position game.PawnPosition) bool {
+	for _, p := range g.FullState(0).Path {
+		if p == position {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canEnter returns true if the current player can enter the room: she/he didn't exit it in this turn.
+func canEnter(g *game.Game, room game.Card) bool {
+	return room != game.NoCard && !visited(g, game.PawnPosition{Room: room})
+}
+
+// doorOf returns the room whose door the cell is in front of, NoCard if none.
+func doorOf(g *game.Game, c cell) game.Card {
+	if cell := game.Card(g.Variant().Board.Cell(c.x, c.y)); game.IsRoom(cell) {
+		return cell
+	}
+
+	return game.NoCard
+}
+
+// doors returns the free hallway cells in front of the doors of a room.
+func doors(g *game.Game, room game.Card) []cell {
+	var r []cell
+
+	board := g.Variant().Board
+
+	for y := 0; y < board.Height(); y++ {
+		for x := 0; x < board.Width(); x++ {
+			c := cell{x, y}
+
+			if doorOf(g, c) == room && walkable(g, c) {
+				r = append(r, c)
+			}
+		}
+	}
+
+	return r
+}
+
+// nextStep returns the first step of the shortest hallway path from a cell to
+// any door of the target room, and the path length. ok is false if no door can be reached.
+func nextStep(g *game.Game, from cell, room game.Card) (step cell, distance int, ok bool) {
+	if doorOf(g, from) == room {
+		return from, 0, true
+	}
+
+	type visit struct {
+		first    cell
+		distance int
+	}
+
+	visited := map[cell]visit{from: {}}
+	queue := []cell{from}
+
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		for _, d := range directions {
+			n := cell{c.x + d.x, c.y + d.y}
+
+			if _, ok := visited[n]; ok || !walkable(g, n) {
+				continue
+			}
+
+			v := visit{first: visited[c].first, distance: visited[c].distance + 1}
+
+			if c == from {
+				v.first = n
+			}
+
+			if doorOf(g, n) == room {
+				return v.first, v.distance, true
+			}
+
+			visited[n] = v
+			queue = append(queue, n)
+		}
+	}
+
+	return cell{}, 0, false
+}
+
+// freeNeighbours returns the cells a pawn in the hallway can step to.
+func freeNeighbours(g *game.Game, from cell) []cell {
+	var r []cell
+
+	for _, d := range directions {
+		n := cell{from.x + d.x, from.y + d.y}
+
+		if walkable(g, n) {
+			r = append(r, n)
+		}
+	}
+
+	return r
+}
+
+func position(g *game.Game, id game.PlayerID) game.PawnPosition {
+	for _, p := range g.PlayerPositions() {
+		if p.PlayerID == id {
+			return p.PawnPosition
+		}
+	}
+
+	return game.PawnPosition{}
+}
+
+// passageFrom returns the room at the other end of the secret passage of room, NoCard if none.
+func passageFrom(g *game.Game, room game.Card) game.Card {
+	if !g.Variant().IsRoom(room) {
+		return game.NoCard
+	}
+
+	for _, other := range g.Variant().Rooms {
+		if g.IsSecretPassage(room, other) {
+			return other
+		}
+	}
+
+	return game.NoCard
+}
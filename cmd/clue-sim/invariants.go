@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// checkInvariants returns a description of every engine invariant the game violates.
+func checkInvariants(g *game.Game, seats []*seat) []string {
+	var violations []string
+
+	state := g.FullState(0)
+
+	if state.State < game.GameStateNewTurn || state.State > game.GameEnded {
+		violations = append(violations, fmt.Sprintf("unexpected state %s", state.State))
+	}
+
+	occupied := make(map[cell]game.PlayerID)
+	starts := make(map[game.PlayerID]cell)
+
+	for _, s := range seats {
+		starts[s.player.ID()] = s.start
+	}
+
+	for _, p := range g.PlayerPositions() {
+		if p.InRoom() {
+			if p.MapX != 0 || p.MapY != 0 {
+				violations = append(violations, fmt.Sprintf("player %d in %s has coords %d %d", p.PlayerID, p.Room, p.MapX, p.MapY))
+			}
+
+			continue
+		}
+
+		c := cell{p.MapX, p.MapY}
+
+		if !g.IsValidPosition(c.x, c.y) || g.Variant().Board.Cell(c.x, c.y) < 0 && c != starts[p.PlayerID] {
+			violations = append(violations, fmt.Sprintf("player %d on a wall %d %d", p.PlayerID, c.x, c.y))
+		}
+
+		if other, ok := occupied[c]; ok {
+			violations = append(violations, fmt.Sprintf("players %d and %d on the same cell %d %d", other, p.PlayerID, c.x, c.y))
+		}
+
+		occupied[c] = p.PlayerID
+	}
+
+	weapons := g.WeaponPositions()
+
+	if len(weapons) != len(g.Variant().Weapons) {
+		violations = append(violations, fmt.Sprintf("%d weapon tokens for %d weapons", len(weapons), len(g.Variant().Weapons)))
+	}
+
+	for _, w := range weapons {
+		if !g.Variant().IsWeapon(w.Weapon) || !g.Variant().IsRoom(w.Room) {
+			violations = append(violations, fmt.Sprintf("weapon token %s in %s", w.Weapon, w.Room))
+		}
+	}
+
+	if state.Query != nil && state.Query.Weapon != game.NoCard {
+		for _, w := range weapons {
+			if w.Weapon == state.Query.Weapon && w.Room != state.Query.Room {
+				violations = append(violations, fmt.Sprintf("suggested weapon %s left in %s, not in the %s", w.Weapon, w.Room, state.Query.Room))
+			}
+		}
+	}
+
+	if state.State == game.GameEnded {
+		return violations
+	}
+
+	current := g.CurrentPlayer()
+
+	if current.FailedSolution() {
+		violations = append(violations, fmt.Sprintf("current player %d already failed the solution", current.ID()))
+	}
+
+	switch state.State {
+	case game.GameStateMove:
+		if state.RemainingSteps <= 0 {
+			violations = append(violations, fmt.Sprintf("move state with %d remaining steps", state.RemainingSteps))
+		}
+
+	case game.GameStateQuery:
+		if answering := g.AnsweringPlayer(); answering == current {
+			violations = append(violations, "current player is answering her/his own query")
+		}
+
+		if pos := position(g, current.ID()); !pos.InRoom() {
+			violations = append(violations, "query state outside a room")
+		}
+	}
+
+	return violations
+}
+
+// checkDeal verifies that decks, face up cards and solution partition all the cards and that
+// they match the commitments published at game start.
+func checkDeal(variant *game.Variant, seats []*seat, faceUp []game.Card, end game.StateUpdate, solutionCommitment, dealCommitment string) []string {
+	var violations []string
+
+	solution := end.Solution
+
+	if solution == nil {
+		return append(violations, "no solution at game end")
+	}
+
+	if end.Seed == nil {
+		return append(violations, "no seed at game end")
+	}
+
+	if game.CommitSolution(*end.Seed, end.Nonce, *solution) != solutionCommitment {
+		violations = append(violations, "revealed solution doesn't match the commitment")
+	}
+
+	if game.CommitDeal(*end.Seed, end.Nonce, end.Decks) != dealCommitment {
+		violations = append(violations, "revealed deal doesn't match the commitment")
+	}
+
+	if !variant.IsCharacter(solution.Character) || !variant.IsWeapon(solution.Weapon) || !variant.IsRoom(solution.Room) {
+		violations = append(violations, fmt.Sprintf("malformed solution %v", *solution))
+	}
+
+	owner := map[game.Card]game.PlayerID{
+		solution.Character: 0,
+		solution.Weapon:    0,
+		solution.Room:      0,
+	}
+
+	for _, card := range faceUp {
+		if other, ok := owner[card]; ok {
+			violations = append(violations, fmt.Sprintf("face up card %s is also owned by %d", card, other))
+		}
+
+		owner[card] = -1
+	}
+
+	min, max := -1, -1
+
+	for _, s := range seats {
+		deck := s.player.Deck()
+
+		if min < 0 || len(deck) < min {
+			min = len(deck)
+		}
+
+		if len(deck) > max {
+			max = len(deck)
+		}
+
+		for _, card := range deck {
+			if !variant.IsCharacter(card) && !variant.IsWeapon(card) && !variant.IsRoom(card) {
+				violations = append(violations, fmt.Sprintf("card %s dealt to player %d is not in the %s variant", card, s.player.ID(), variant.Name))
+			}
+
+			if other, ok := owner[card]; ok {
+				violations = append(violations, fmt.Sprintf("card %s dealt to player %d is also owned by %d (0 is the solution)", card, s.player.ID(), other))
+			}
+
+			owner[card] = s.player.ID()
+		}
+	}
+
+	if len(faceUp) > 0 && len(faceUp) >= len(seats) {
+		violations = append(violations, fmt.Sprintf("%d face up cards for %d players", len(faceUp), len(seats)))
+	}
+
+	if max-min > 1 || len(faceUp) > 0 && max != min {
+		violations = append(violations, fmt.Sprintf("unbalanced deal: decks of %d to %d cards", min, max))
+	}
+
+	for _, card := range variant.Cards() {
+		if _, ok := owner[card]; !ok {
+			violations = append(violations, fmt.Sprintf("card %s not dealt", card))
+		}
+	}
+
+	return violations
+}
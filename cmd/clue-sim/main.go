@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+type config struct {
+	games      int
+	variant    *game.Variant
+	rules      game.Rules
+	seed       int64
+	players    int
+	strategies []string
+	maxActions int
+	verbose    bool
+	dump       string
+}
+
+// outcome is the result of a simulated game.
+type outcome struct {
+	seed       int64
+	turns      int
+	actions    int
+	stalemate  string
+	winnerSeat int
+	winnerName string
+	byDefault  bool
+	violations []string
+	engineErr  error
+	// notation of the game, see game.WriteNotation
+	notation []byte
+}
+
+func main() {
+	cfg := config{}
+
+	flag.IntVar(&cfg.games, "games", 1000, "number of games to play")
+	flag.Int64Var(&cfg.seed, "seed", 1, "seed of the first game, the following ones increment it")
+	variantName := flag.String("variant", game.ClassicVariant, "game variant, see game.Variants")
+	rulesNames := flag.String("rules", "", "space separated house rules, see game.Rules.String")
+	flag.IntVar(&cfg.players, "players", 4, "players per table, 2 to the variant maximum")
+	strategyNames := flag.String("strategies", "notebook", "comma separated strategies assigned to seats in join order, cycling: notebook, random")
+	flag.IntVar(&cfg.maxActions, "max-actions", 5000, "actions after which a game is considered a stalemate")
+	flag.BoolVar(&cfg.verbose, "v", false, "print every violation and engine error")
+	flag.StringVar(&cfg.dump, "dump", "", "directory where to write the notation of the games with errors, violations or stalemates")
+
+	flag.Parse()
+
+	var (
+		ok  bool
+		err error
+	)
+
+	if cfg.variant, ok = game.VariantByName(*variantName); !ok {
+		log.Fatalf("unknown variant: %s", *variantName)
+	}
+
+	if cfg.rules, err = game.ParseRules(*rulesNames); err != nil {
+		log.Fatalf("bad rules: %v", err)
+	}
+
+	if cfg.players < 2 || cfg.players > cfg.variant.MaxPlayers {
+		log.Fatalf("players must be between 2 and %d", cfg.variant.MaxPlayers)
+	}
+
+	cfg.strategies = strings.Split(*strategyNames, ",")
+
+	for _, name := range cfg.strategies {
+		if strategies[name] == nil {
+			log.Fatalf("unknown strategy: %s", name)
+		}
+	}
+
+	var outcomes []outcome
+
+	for i := 0; i < cfg.games; i++ {
+		outcomes = append(outcomes, play(cfg, cfg.seed+int64(i)))
+	}
+
+	if cfg.dump != "" {
+		if err := dump(cfg.dump, outcomes); err != nil {
+			log.Fatalf("dump failed: %v", err)
+		}
+	}
+
+	if report(cfg, outcomes) {
+		os.Exit(1)
+	}
+}
+
+func play(cfg config, seed int64) outcome {
+	rng := rand.New(rand.NewSource(seed))
+	g := game.New(fmt.Sprintf("SIM%d", seed), cfg.variant, cfg.rules, game.SeedFromID(seed))
+
+	r := playGame(cfg, g, rng)
+	r.seed = seed
+
+	var notation bytes.Buffer
+
+	if err := g.WriteNotation(&notation); err != nil {
+		r.violations = append(r.violations, fmt.Sprintf("notation export failed: %v", err))
+		return r
+	}
+
+	r.notation = notation.Bytes()
+
+	if r.engineErr != nil {
+		return r
+	}
+
+	// the notation has to rebuild the very same game
+	rebuilt, err := game.ReadNotation(bytes.NewReader(r.notation))
+
+	if err != nil {
+		r.violations = append(r.violations, fmt.Sprintf("notation import failed: %v", err))
+		return r
+	}
+
+	var again bytes.Buffer
+
+	rebuilt.WriteNotation(&again)
+
+	if !bytes.Equal(r.notation, again.Bytes()) {
+		r.violations = append(r.violations, "notation round trip produced a different game")
+	}
+
+	return r
+}
+
+func playGame(cfg config, g *game.Game, rng *rand.Rand) outcome {
+	r := outcome{
+		winnerSeat: -1,
+	}
+
+	seats := make(map[game.PlayerID]*seat)
+	var joinOrder []*seat
+
+	for i := 0; i < cfg.players; i++ {
+		player, err := g.AddPlayer()
+
+		if err != nil {
+			r.engineErr = err
+			return r
+		}
+
+		if _, err := g.SelectCharacter(player, cfg.variant.Characters[i]); err != nil {
+			r.engineErr = err
+			return r
+		}
+
+		s := newSeat(player, strategies[cfg.strategies[i%len(cfg.strategies)]](), rng)
+		seats[player.ID()] = s
+		joinOrder = append(joinOrder, s)
+	}
+
+	for _, s := range joinOrder {
+		started, err := g.VoteStart(s.player, true)
+
+		if err != nil {
+			r.engineErr = err
+			return r
+		}
+
+		if started {
+			if err := g.Start(); err != nil {
+				r.engineErr = err
+				return r
+			}
+		}
+	}
+
+	for _, s := range joinOrder {
+		for _, card := range s.player.Deck() {
+			s.seen[card] = true
+		}
+
+		for _, card := range g.FaceUpCards() {
+			s.seen[card] = true
+		}
+
+		pos := position(g, s.player.ID())
+		s.start = cell{pos.MapX, pos.MapY}
+	}
+
+	solutionCommitment, dealCommitment := g.SolutionCommitment(), g.DealCommitment()
+
+	var last []*game.MoveRecord
+
+	for g.FullState(0).State != game.GameEnded {
+		if r.actions >= cfg.maxActions {
+			r.stalemate = "too many actions"
+			return r
+		}
+
+		records, err := step(g, seats)
+
+		if err != nil {
+			r.engineErr = fmt.Errorf("action %d, state %s: %w", r.actions, g.FullState(0).State, err)
+			return r
+		}
+
+		r.actions++
+
+		for _, record := range records {
+			if t := record.Move.MoveType(); t == game.RollDices || t == game.UsePassage || t == game.StayInRoom {
+				r.turns++
+			}
+
+			for _, s := range seats {
+				s.learn(*record)
+			}
+		}
+
+		for _, v := range checkInvariants(g, joinOrder) {
+			r.violations = append(r.violations, fmt.Sprintf("action %d: %s", r.actions, v))
+		}
+
+		last = records
+	}
+
+	r.violations = append(r.violations, checkDeal(g.Variant(), joinOrder, g.FaceUpCards(), g.FullState(0), solutionCommitment, dealCommitment)...)
+
+	winner := last[len(last)-1].PlayerID
+	r.winnerName = seats[winner].strategy.name()
+	// the last player left after the others failed wins without accusing
+	r.byDefault = len(last) > 1
+
+	for i, id := range g.PlayerTurnSequence() {
+		if id == winner {
+			r.winnerSeat = i
+		}
+	}
+
+	return r
+}
+
+// step performs the next action of the game.
+func step(g *game.Game, seats map[game.PlayerID]*seat) ([]*game.MoveRecord, error) {
+	state := g.FullState(0)
+	current := seats[g.CurrentPlayer().ID()]
+
+	switch state.State {
+	case game.GameStateNewTurn:
+		if current.strategy.stay(g, current) {
+			return one(g.StayInRoom())
+		}
+
+		if room := current.strategy.passage(g, current); room != game.NoCard {
+			return one(g.UsePassage(room))
+		}
+
+		return one(g.RollDices())
+
+	case game.GameStateMove:
+		room, to, ok := current.strategy.move(g, current)
+
+		if !ok {
+			// no cell to move to: the player ends her/his movement
+			return one(g.StopMoving())
+		}
+
+		return one(g.Move(room, to.x, to.y))
+
+	case game.GameStateQuery:
+		if answering := g.AnsweringPlayer(); answering != nil {
+			s := seats[answering.ID()]
+
+			return g.Reveal(s.strategy.reveal(g, s, *state.Query))
+		}
+
+		character, weapon := current.strategy.suggest(g, current)
+
+		records, err := g.QuerySolution(character, weapon)
+
+		if err == game.RepeatedRoomSuggestion {
+			return one(g.Pass())
+		}
+
+		return records, err
+
+	case game.GameStateTrySolution:
+		declaration := current.strategy.accuse(g, current)
+
+		if declaration == nil {
+			return one(g.Pass())
+		}
+
+		return g.CheckSolution(declaration.Character, declaration.Room, declaration.Weapon)
+	}
+
+	return nil, fmt.Errorf("unexpected state %s", state.State)
+}
+
+func one(record *game.MoveRecord, err error) ([]*game.MoveRecord, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return []*game.MoveRecord{record}, nil
+}
+
+// dump writes the notation of the games with errors, violations or stalemates.
+func dump(dir string, outcomes []outcome) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, o := range outcomes {
+		if o.engineErr == nil && o.stalemate == "" && len(o.violations) == 0 {
+			continue
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("seed-%d.clue", o.seed)), o.notation, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// report prints the simulation statistics, it returns true if the engine misbehaved.
+func report(cfg config, outcomes []outcome) bool {
+	var turns []int
+	seatWins := make([]int, cfg.players)
+	strategyWins := make(map[string]int)
+	stalemates := make(map[string]int)
+	byDefault := 0
+	failed := 0
+
+	for _, o := range outcomes {
+		if len(o.violations) > 0 || o.engineErr != nil {
+			failed++
+
+			if cfg.verbose || failed <= 5 {
+				fmt.Printf("seed %d:\n", o.seed)
+
+				if o.engineErr != nil {
+					fmt.Printf("  engine error: %v\n", o.engineErr)
+				}
+
+				for _, v := range o.violations {
+					fmt.Printf("  %s\n", v)
+				}
+			}
+		}
+
+		if o.engineErr != nil {
+			continue
+		}
+
+		if o.stalemate != "" {
+			stalemates[o.stalemate]++
+			continue
+		}
+
+		turns = append(turns, o.turns)
+		seatWins[o.winnerSeat]++
+		strategyWins[o.winnerName]++
+
+		if o.byDefault {
+			byDefault++
+		}
+	}
+
+	fmt.Printf("games: %d, completed: %d, with engine errors or violations: %d\n", len(outcomes), len(turns), failed)
+
+	for reason, n := range stalemates {
+		fmt.Printf("stalemates (%s): %d\n", reason, n)
+	}
+
+	if len(turns) == 0 {
+		return failed > 0
+	}
+
+	sort.Ints(turns)
+
+	sum := 0
+	for _, t := range turns {
+		sum += t
+	}
+
+	fmt.Printf("turns: min %d, mean %.1f, median %d, p90 %d, max %d\n",
+		turns[0], float64(sum)/float64(len(turns)), turns[len(turns)/2], turns[len(turns)*9/10], turns[len(turns)-1])
+
+	// about 20 buckets, at least 10 turns wide
+	width := (turns[len(turns)-1]/20 + 9) / 10 * 10
+	if width == 0 {
+		width = 10
+	}
+
+	histogram := make(map[int]int)
+	for _, t := range turns {
+		histogram[t/width*width]++
+	}
+
+	var buckets []int
+	for b := range histogram {
+		buckets = append(buckets, b)
+	}
+	sort.Ints(buckets)
+
+	for _, b := range buckets {
+		fmt.Printf("  %4d-%4d %6d %s\n", b, b+width-1, histogram[b], strings.Repeat("#", histogram[b]*60/len(turns)))
+	}
+
+	fmt.Println("win rate by seat (turn order):")
+
+	for i, wins := range seatWins {
+		fmt.Printf("  %d: %5.1f%%\n", i+1, 100*float64(wins)/float64(len(turns)))
+	}
+
+	fmt.Println("wins by strategy:")
+
+	for name, wins := range strategyWins {
+		fmt.Printf("  %s: %d\n", name, wins)
+	}
+
+	fmt.Printf("won by default (all the others failed): %d\n", byDefault)
+
+	return failed > 0
+}
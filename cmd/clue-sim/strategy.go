@@ -0,0 +1,372 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// seat is a simulated player: the engine player, its strategy and what it knows.
+type seat struct {
+	player   *game.Player
+	strategy strategy
+	rand     *rand.Rand
+	// seen are the cards known not to be in the solution.
+	seen map[game.Card]bool
+	// solution are the cards known to be in the solution.
+	solution map[game.Card]bool
+	// query is the suggestion of the player still waiting for an answer.
+	query *game.Declaration
+	// target is the room the player is walking to.
+	target game.Card
+	// start is the cell the pawn begins on, outside the hallway.
+	start cell
+}
+
+// strategy decides what a simulated player does.
+type strategy interface {
+	name() string
+	// stay returns true to suggest without rolling the dices in the room a suggestion moved the player in.
+	stay(g *game.Game, s *seat) bool
+	// passage returns the room to reach through a secret passage instead of
+	// rolling the dices, NoCard to roll.
+	passage(g *game.Game, s *seat) game.Card
+	// move returns the room to enter, or NoCard and the hallway cell to step on.
+	// ok is false if the player can't move.
+	move(g *game.Game, s *seat) (room game.Card, to cell, ok bool)
+	// suggest returns the character and weapon to query in the room the player is in.
+	suggest(g *game.Game, s *seat) (character, weapon game.Card)
+	// reveal returns the card to show to the querying player, NoCard if none.
+	reveal(g *game.Game, s *seat, query game.Declaration) game.Card
+	// accuse returns the solution to declare, nil to pass.
+	accuse(g *game.Game, s *seat) *game.Declaration
+}
+
+var strategies = map[string]func() strategy{
+	"notebook": func() strategy { return notebook{} },
+	"random":   func() strategy { return randomPlayer{} },
+}
+
+func newSeat(player *game.Player, strategy strategy, rand *rand.Rand) *seat {
+	return &seat{
+		player:   player,
+		strategy: strategy,
+		rand:     rand,
+		seen:     make(map[game.Card]bool),
+		solution: make(map[game.Card]bool),
+	}
+}
+
+// learn updates the player knowledge with a record as seen by her/him.
+func (s *seat) learn(record game.MoveRecord) {
+	visible := record.AsMessageFor(s.player)
+
+	switch move := visible.Move.(type) {
+	case *game.QuerySolutionMove:
+		if record.PlayerID == s.player.ID() {
+			s.query = record.StateDelta.Query
+		}
+
+	case *game.RevealCardMove:
+		if game.IsCard(move.Card) {
+			s.seen[move.Card] = true
+		}
+
+		s.query = nil
+
+	case *game.NoCardToRevealMove:
+		if s.query == nil || record.StateDelta.State != game.GameStateTrySolution {
+			break
+		}
+
+		if s.eliminatedSkipped() {
+			// the skipped players may hold the cards
+			s.query = nil
+			break
+		}
+
+		// nobody could answer: the queried cards the player hasn't seen are the solution
+		for _, card := range []game.Card{s.query.Character, s.query.Weapon, s.query.Room} {
+			if !s.seen[card] {
+				s.solution[card] = true
+			}
+		}
+
+		s.query = nil
+	}
+}
+
+// eliminatedSkipped returns true if the house rules skip the eliminated players
+// when answering and some player has been eliminated.
+func (s *seat) eliminatedSkipped() bool {
+	g := s.player.Game()
+
+	if !g.Rules().SkipEliminatedPlayers {
+		return false
+	}
+
+	skipped := false
+
+	g.Players(func(player *game.Player) {
+		skipped = skipped || player.FailedSolution()
+	})
+
+	return skipped
+}
+
+func (s *seat) variant() *game.Variant {
+	return s.player.Game().Variant()
+}
+
+// candidates returns the cards of a kind that can still be in the solution.
+func (s *seat) candidates(kind func(game.Card) bool) []game.Card {
+	var r []game.Card
+
+	for card := range s.solution {
+		if kind(card) {
+			return []game.Card{card}
+		}
+	}
+
+	for _, card := range game.AllCards() {
+		if kind(card) && !s.seen[card] {
+			r = append(r, card)
+		}
+	}
+
+	return r
+}
+
+func (s *seat) pick(cards []game.Card) game.Card {
+	return cards[s.rand.Intn(len(cards))]
+}
+
+// heldCards returns the queried cards the player holds.
+func (s *seat) heldCards(query game.Declaration) []game.Card {
+	var r []game.Card
+
+	for _, card := range []game.Card{query.Character, query.Weapon, query.Room} {
+		if s.player.HasCard(card) {
+			r = append(r, card)
+		}
+	}
+
+	return r
+}
+
+// certainSolution returns the solution if only one candidate per kind is left.
+func (s *seat) certainSolution() *game.Declaration {
+	characters := s.candidates(s.variant().IsCharacter)
+	weapons := s.candidates(s.variant().IsWeapon)
+	rooms := s.candidates(s.variant().IsRoom)
+
+	if len(characters) != 1 || len(weapons) != 1 || len(rooms) != 1 {
+		return nil
+	}
+
+	return &game.Declaration{
+		Character: characters[0],
+		Weapon:    weapons[0],
+		Room:      rooms[0],
+	}
+}
+
+// notebook walks to the rooms it doesn't know, suggests unknown cards and accuses
+// only when certain.
+type notebook struct{}
+
+func (notebook) name() string {
+	return "notebook"
+}
+
+func (notebook) move(g *game.Game, s *seat) (game.Card, cell, bool) {
+	pos := position(g, s.player.ID())
+
+	if s.target == game.NoCard || s.seen[s.target] || s.target == pos.Room {
+		s.target = notebookTarget(s, pos.Room)
+	}
+
+	if pos.InRoom() {
+		if !s.seen[pos.Room] && s.player.CanSuggest() {
+			return pos.Room, cell{}, true
+		}
+
+		if g.IsSecretPassage(pos.Room, s.target) {
+			return s.target, cell{}, true
+		}
+
+		best, bestDistance := cell{}, -1
+
+		for _, door := range doors(g, pos.Room) {
+			if _, distance, ok := nextStep(g, door, s.target); ok && (bestDistance < 0 || distance < bestDistance) {
+				best, bestDistance = door, distance
+			}
+		}
+
+		if bestDistance < 0 {
+			// every door is blocked or leads nowhere
+			return pos.Room, cell{}, true
+		}
+
+		return game.NoCard, best, true
+	}
+
+	from := cell{pos.MapX, pos.MapY}
+
+	if door := doorOf(g, from); canEnter(g, door) && (door == s.target || !s.seen[door]) {
+		return door, cell{}, true
+	}
+
+	if step, _, ok := nextStep(g, from, s.target); ok {
+		return game.NoCard, step, true
+	}
+
+	return randomStep(g, s, from)
+}
+
+func (notebook) stay(g *game.Game, s *seat) bool {
+	pos := position(g, s.player.ID())
+
+	return s.player.PulledBySuggestion() && !s.seen[pos.Room] && s.player.CanSuggest()
+}
+
+func (notebook) passage(g *game.Game, s *seat) game.Card {
+	pos := position(g, s.player.ID())
+
+	if room := passageFrom(g, pos.Room); room != game.NoCard && !s.seen[room] {
+		s.target = room
+		return room
+	}
+
+	return game.NoCard
+}
+
+func notebookTarget(s *seat, current game.Card) game.Card {
+	rooms := s.candidates(s.variant().IsRoom)
+
+	var others []game.Card
+
+	for _, room := range rooms {
+		if room != current {
+			others = append(others, room)
+		}
+	}
+
+	if len(others) == 0 {
+		return rooms[0]
+	}
+
+	return s.pick(others)
+}
+
+func (notebook) suggest(g *game.Game, s *seat) (game.Card, game.Card) {
+	return s.pick(s.candidates(s.variant().IsCharacter)), s.pick(s.candidates(s.variant().IsWeapon))
+}
+
+func (notebook) reveal(g *game.Game, s *seat, query game.Declaration) game.Card {
+	held := s.heldCards(query)
+
+	if len(held) == 0 {
+		return game.NoCard
+	}
+
+	return s.pick(held)
+}
+
+func (notebook) accuse(g *game.Game, s *seat) *game.Declaration {
+	return s.certainSolution()
+}
+
+// randomPlayer wanders, suggests random cards and sometimes accuses without being sure.
+type randomPlayer struct{}
+
+func (randomPlayer) name() string {
+	return "random"
+}
+
+func (randomPlayer) move(g *game.Game, s *seat) (game.Card, cell, bool) {
+	pos := position(g, s.player.ID())
+
+	if pos.InRoom() {
+		options := doors(g, pos.Room)
+
+		switch n := s.rand.Intn(len(options) + 2); {
+		case n < len(options):
+			return game.NoCard, options[n], true
+		case n == len(options):
+			for _, room := range s.candidates(s.variant().IsRoom) {
+				if g.IsSecretPassage(pos.Room, room) {
+					return room, cell{}, true
+				}
+			}
+		}
+
+		return pos.Room, cell{}, true
+	}
+
+	from := cell{pos.MapX, pos.MapY}
+
+	if door := doorOf(g, from); canEnter(g, door) && s.rand.Intn(2) == 0 {
+		return door, cell{}, true
+	}
+
+	return randomStep(g, s, from)
+}
+
+func (randomPlayer) stay(g *game.Game, s *seat) bool {
+	return s.player.PulledBySuggestion() && s.player.CanSuggest() && s.rand.Intn(2) == 0
+}
+
+func (randomPlayer) passage(g *game.Game, s *seat) game.Card {
+	pos := position(g, s.player.ID())
+
+	if room := passageFrom(g, pos.Room); room != game.NoCard && s.rand.Intn(3) == 0 {
+		return room
+	}
+
+	return game.NoCard
+}
+
+func randomStep(g *game.Game, s *seat, from cell) (game.Card, cell, bool) {
+	options := freeNeighbours(g, from)
+
+	if len(options) == 0 {
+		if door := doorOf(g, from); canEnter(g, door) {
+			return door, cell{}, true
+		}
+
+		return game.NoCard, cell{}, false
+	}
+
+	return game.NoCard, options[s.rand.Intn(len(options))], true
+}
+
+func (randomPlayer) suggest(g *game.Game, s *seat) (game.Card, game.Card) {
+	return s.pick(s.candidates(s.variant().IsCharacter)), s.pick(s.candidates(s.variant().IsWeapon))
+}
+
+func (randomPlayer) reveal(g *game.Game, s *seat, query game.Declaration) game.Card {
+	held := s.heldCards(query)
+
+	if len(held) == 0 {
+		return game.NoCard
+	}
+
+	return s.pick(held)
+}
+
+func (randomPlayer) accuse(g *game.Game, s *seat) *game.Declaration {
+	if solution := s.certainSolution(); solution != nil {
+		return solution
+	}
+
+	if s.rand.Intn(50) != 0 {
+		return nil
+	}
+
+	return &game.Declaration{
+		Character: s.pick(s.candidates(s.variant().IsCharacter)),
+		Weapon:    s.pick(s.candidates(s.variant().IsWeapon)),
+		Room:      s.pick(s.candidates(s.variant().IsRoom)),
+	}
+}
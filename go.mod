@@ -1,5 +1,5 @@
 module github.com/makeroo/my_clue_be
 
-go 1.14
+go 1.22
 
 require github.com/gorilla/websocket v1.4.2
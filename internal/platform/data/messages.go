@@ -1,9 +1,27 @@
 package data
 
 import (
+	"encoding/json"
+
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 )
 
+// Websocket subprotocols negotiated on connection, the preferred one first.
+// A client not asking for any subprotocol speaks ProtocolLegacy.
+const (
+	// ProtocolEnvelope sends each message as a single Envelope frame.
+	ProtocolEnvelope = "clue.v2"
+	// ProtocolLegacy sends each message as two frames: MessageHeader and then,
+	// if the message has a payload, the body.
+	// The payloads added afterwards, eg. the one of create_game, can't be sent:
+	// the server doesn't read them and uses the defaults, ie. a classic game
+	// with the default rules and an unguessable seed.
+	ProtocolLegacy = "clue.v1"
+)
+
+// Protocols lists the supported subprotocols in order of preference.
+var Protocols = []string{ProtocolEnvelope, ProtocolLegacy}
+
 // MessageType is an enum of all the message types defined by My Clue BE API.
 type MessageType string
 
@@ -35,6 +53,15 @@ const (
 	// MessageMoveRequest is a constant for move request.
 	MessageMoveRequest = "move"
 
+	// MessageStopMovingRequest is a constant for stop moving request.
+	MessageStopMovingRequest = "stop_moving"
+
+	// MessageUsePassageRequest is a constant for use passage request.
+	MessageUsePassageRequest = "use_passage"
+
+	// MessageStayInRoomRequest is a constant for stay in room request.
+	MessageStayInRoomRequest = "stay_in_room"
+
 	// MessageQuerySolutionRequest is a constant for query solution request.
 	MessageQuerySolutionRequest = "query_solution"
 
@@ -47,6 +74,9 @@ const (
 	// MessagePassRequest is a constant for pass request.
 	MessagePassRequest = "pass"
 
+	// MessageSetAutoAnswerRequest is a constant for set auto answer request.
+	MessageSetAutoAnswerRequest = "set_auto_answer"
+
 	// MessageNotifyUserState is a constant for user state notification.
 	MessageNotifyUserState = "notify_user_state"
 
@@ -56,6 +86,12 @@ const (
 	// MessageNotifyMoveRecord is a constant for move record notification.
 	MessageNotifyMoveRecord = "notify_move_record"
 
+	// MessageNotifyFullState is a constant for full state notification.
+	MessageNotifyFullState = "notify_full_state"
+
+	// MessageNotifyAnswerOptions is a constant for answer options notification.
+	MessageNotifyAnswerOptions = "notify_answer_options"
+
 	// MessageError is a constant for error notification.
 	MessageError = "error"
 
@@ -63,13 +99,31 @@ const (
 	MessageEmptyResponse = "empty"
 )
 
+// ServerMessages maps the messages sent by the server to an instance of their payload,
+// nil if the message has none.
+// Requests payloads are defined by the RequestHandlers registered in the server.
+var ServerMessages = map[MessageType]interface{}{
+	MessageSignInResponse:      SignInResponse{},
+	MessageCreateGameResponse:  CreateGameResponse{},
+	MessageJoinGameResponse:    JoinGameResponse{},
+	MessageNotifyUserState:     NotifyUserState{},
+	MessageNotifyGameStarted:   NotifyGameStarted{},
+	MessageNotifyMoveRecord:    game.MoveRecord{},
+	MessageNotifyFullState:     NotifyFullState{},
+	MessageNotifyAnswerOptions: NotifyAnswerOptions{},
+	MessageError:               NotifyError{},
+	MessageEmptyResponse:       nil,
+}
+
 // SignInRequest describes a sign in request.
 // If only Name is defined, ie. non empty, then this is a register request and a new token will be
 // assigned and returned in SignInResponse.
 // If Token is defined, ie. non empty, then this is a sign in request.
+// Language, eg. it or en-US, chooses the language of the error messages.
 type SignInRequest struct {
-	Name  string `json:"name"`
-	Token string `json:"token"`
+	Name     string `json:"name"`
+	Token    string `json:"token"`
+	Language string `json:"language,omitempty"`
 }
 
 // SignInResponse describes a sign in response.
@@ -88,28 +142,54 @@ type GamePlayer struct {
 
 // GameSynopsis is a preview of a joined game.
 type GameSynopsis struct {
-	ID   string           `json:"game_id"`
-	Game game.StateUpdate `json:"game"`
+	ID      string           `json:"game_id"`
+	Variant string           `json:"variant"`
+	Rules   game.Rules       `json:"rules"`
+	Game    game.StateUpdate `json:"game"`
 	//	Character game.Card     `json:"character,omitempty"`
 	MyID    game.PlayerID `json:"my_player_id"`
 	Players []GamePlayer  `json:"players,omitempty"`
 }
 
+// CreateGameRequest describes a create game request.
+// Seed, if defined, is the id of a reproducible game: same id and players give the
+// same player order, solution, deal and dices, see game.SeedFromID. Anyone knowing the id
+// can work out the solution, so it is meant for replays and tests. Otherwise an unguessable
+// seed is drawn. Either way, the seed is revealed when the game ends.
+// Variant is the name of the game variant, see game.Variants. Default is classic.
+// Rules are the house rules, the zero value being the default ones.
+// It is sent only by ProtocolEnvelope clients: legacy ones send no body, see ProtocolLegacy.
+type CreateGameRequest struct {
+	Seed    *int64     `json:"seed,omitempty"`
+	Variant string     `json:"variant,omitempty"`
+	Rules   game.Rules `json:"rules"`
+}
+
 // CreateGameResponse describes a create game response.
 type CreateGameResponse struct {
-	GameID string        `json:"game_id"`
-	MyID   game.PlayerID `json:"my_player_id"`
+	GameID  string        `json:"game_id"`
+	Variant string        `json:"variant"`
+	Rules   game.Rules    `json:"rules"`
+	MyID    game.PlayerID `json:"my_player_id"`
 }
 
 // JoinGameRequest describes a join game request.
+// A client coming back to a game sets LastSeq to the sequence number of the last
+// notification it received so that only the missed ones are sent again.
 type JoinGameRequest struct {
-	GameID string `json:"game_id"`
+	GameID  string `json:"game_id"`
+	LastSeq int    `json:"last_seq,omitempty"`
 }
 
 // JoinGameResponse describes a join game response.
+// Seq is the sequence number of the last notification sent for the game.
 type JoinGameResponse struct {
+	GameID  string            `json:"game_id"`
+	Variant string            `json:"variant"`
+	Rules   game.Rules        `json:"rules"`
 	Players []NotifyUserState `json:"players"`
 	MyID    game.PlayerID     `json:"my_player_id"`
+	Seq     int               `json:"seq"`
 }
 
 // SelectCharacterRequest describes a select char request.
@@ -129,6 +209,11 @@ type MoveRequest struct {
 	MapY      int       `json:"map_y"`
 }
 
+// UsePassageRequest describes a use passage request.
+type UsePassageRequest struct {
+	Room game.Card `json:"room"`
+}
+
 // QuerySolutionRequest describes a query solution request.
 type QuerySolutionRequest struct {
 	Character game.Card `json:"character"`
@@ -141,14 +226,28 @@ type RevealRequest struct {
 	Card game.Card `json:"card,omitempty"`
 }
 
+// SetAutoAnswerRequest describes a set auto answer request: if AutoAnswer is true
+// the server answers for the player when she/he has at most one of the queried cards.
+type SetAutoAnswerRequest struct {
+	AutoAnswer bool `json:"auto_answer"`
+}
+
 // DeclareSolutionRequest describes a declare solution request.
 type DeclareSolutionRequest struct {
 	game.Declaration
 }
 
-// NotifyError is an error message.
+// NotifyError is an error message, the response to a rejected request.
+// Code is stable and meant for programs, see game.Error, while Message is meant for
+// humans and is in the language chosen at sign in.
+// Details, if any, help to recover from the error, eg. the states the request is valid in.
+// Internal errors have code game.InternalError and the ErrorID they have been logged with.
 type NotifyError struct {
-	Error string `json:"error"`
+	Code    game.Error         `json:"code"`
+	Message string             `json:"message"`
+	Details *game.ErrorDetails `json:"details,omitempty"`
+	ReqID   int                `json:"req_id"`
+	ErrorID string             `json:"error_id,omitempty"`
 }
 
 /*
@@ -180,6 +279,31 @@ type NotifyUserState struct {
 type NotifyGameStarted struct {
 	Deck         []game.Card     `json:"deck"`
 	PlayersOrder []game.PlayerID `json:"players_order"`
+	// FaceUp are the leftover cards shown to everyone, see game.Rules.
+	FaceUp []game.Card `json:"face_up,omitempty"`
+
+	// SolutionCommitment and DealCommitment prove that solution and deal
+	// don't change during the game, see game.CommitSolution and game.CommitDeal.
+	SolutionCommitment string `json:"solution_commitment"`
+	DealCommitment     string `json:"deal_commitment"`
+}
+
+// NotifyFullState is sent to a player coming back to a game when the notifications
+// she/he missed are too old to be sent again. It replaces whatever the client knows
+// of the game.
+type NotifyFullState struct {
+	NotifyGameStarted
+	Players []NotifyUserState `json:"players"`
+	Game    game.StateUpdate  `json:"game"`
+}
+
+// NotifyAnswerOptions is sent to the player due to answer a query: Cards are the
+// queried cards she/he can reveal, none if she/he has to pass.
+// It is private to the answering player and has no sequence number: it is sent
+// again when the player joins the game back.
+type NotifyAnswerOptions struct {
+	Query game.Declaration `json:"query"`
+	Cards []game.Card      `json:"cards"`
 }
 
 // MessageFrame is a message going from fe to be or vicersa.
@@ -190,8 +314,21 @@ type MessageFrame struct {
 	Body   interface{}
 }
 
+// Envelope is a message sent as a single frame: the header fields and the body.
+// Body is omitted if the message has no payload.
+type Envelope struct {
+	MessageHeader
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
 // MessageHeader is the first value  sent throught the web socket.
+// A websocket can follow many games at once: GameID tells which one a game
+// related request or notification refers to.
+// Seq numbers the notifications of a game, starting from 1.
 type MessageHeader struct {
 	Type  MessageType `json:"type"`
 	ReqID int         `json:"req_id"`
+	// GameID can be omitted by the requests about a game if the connection follows only that one.
+	GameID string `json:"game_id,omitempty"`
+	Seq    int    `json:"seq,omitempty"`
 }
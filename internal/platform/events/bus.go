@@ -0,0 +1,200 @@
+package events
+
+import (
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Subscriber receives the published events.
+type Subscriber func(Event)
+
+// Delivery tells what to do when a subscriber is too slow to keep up with the events.
+type Delivery string
+
+const (
+	// DeliverLatest drops the events published while the subscriber queue is full, see
+	// Bus.Dropped. It suits subscribers that can miss some events, eg. stats and metrics.
+	DeliverLatest Delivery = "latest"
+	// DeliverAll queues every event, however many are waiting: the memory used by the queue
+	// grows as long as the subscriber lags behind. It suits subscribers that need every event,
+	// eg. persistence and bots.
+	DeliverAll Delivery = "all"
+)
+
+// subscription is a subscriber along with the queue of the events it has still to receive.
+type subscription struct {
+	id         int
+	subscriber Subscriber
+	delivery   Delivery
+
+	mu    sync.Mutex
+	queue []Event
+	// ready signals the subscription goroutine that there are queued events.
+	ready chan struct{}
+	// cancelled is closed when the subscription is cancelled, stopping its goroutine.
+	cancelled chan struct{}
+	dropped   atomic.Uint64
+}
+
+// Bus delivers the published events to every subscriber: stats, metrics and webhooks,
+// as well as persistence and bots. The players of a game are notified by the server directly
+// and don't depend on it.
+//
+// Publish doesn't wait for the subscribers: each one receives the events on its own goroutine,
+// in the order they have been published, through a queue of its own. What happens when the
+// queue of a subscriber too slow to keep up grows depends on the Delivery it has chosen.
+type Bus struct {
+	mu sync.Mutex
+	// subscriptions is replaced, never modified, so that Publish can range over it unlocked.
+	subscriptions []*subscription
+	lastID        int
+	queueSize     int
+	dropped       atomic.Uint64
+}
+
+// NewBus returns a bus without subscribers. Each DeliverLatest subscriber can have queueSize
+// events waiting to be received.
+func NewBus(queueSize int) *Bus {
+	return &Bus{
+		queueSize: queueSize,
+	}
+}
+
+// Subscribe adds a subscriber and returns the function cancelling the subscription.
+// The subscriber is called by a goroutine of its own, never concurrently: the events of
+// a game are received in the order they happened.
+func (bus *Bus) Subscribe(subscriber Subscriber, delivery Delivery) func() {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.lastID++
+
+	s := &subscription{
+		id:         bus.lastID,
+		subscriber: subscriber,
+		delivery:   delivery,
+		ready:      make(chan struct{}, 1),
+		cancelled:  make(chan struct{}),
+	}
+
+	bus.subscriptions = append(bus.subscriptions[:len(bus.subscriptions):len(bus.subscriptions)], s)
+
+	go s.run()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			bus.unsubscribe(s.id)
+			close(s.cancelled)
+		})
+	}
+}
+
+func (bus *Bus) unsubscribe(id int) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	var subscriptions []*subscription
+
+	for _, s := range bus.subscriptions {
+		if s.id != id {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+
+	bus.subscriptions = subscriptions
+}
+
+// Publish queues an event for every subscriber and returns without waiting for them.
+func (bus *Bus) Publish(event Event) {
+	bus.mu.Lock()
+	subscriptions := bus.subscriptions
+	bus.mu.Unlock()
+
+	for _, s := range subscriptions {
+		if s.push(event, bus.queueSize) {
+			continue
+		}
+
+		bus.dropped.Add(1)
+
+		if s.dropped.Add(1) == 1 {
+			log.Println("event subscriber too slow, dropping events: subscription=", s.id, "kind=", event.Kind(), "game=", event.Game())
+		}
+	}
+}
+
+// Dropped returns the number of events not delivered because a DeliverLatest subscriber
+// queue was full.
+func (bus *Bus) Dropped() uint64 {
+	return bus.dropped.Load()
+}
+
+// push queues an event. It returns false if the event has been dropped because the queue is full.
+func (s *subscription) push(event Event, queueSize int) bool {
+	s.mu.Lock()
+
+	if s.delivery != DeliverAll && len(s.queue) >= queueSize {
+		s.mu.Unlock()
+
+		return false
+	}
+
+	s.queue = append(s.queue, event)
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// pop returns the queued events, emptying the queue.
+func (s *subscription) pop() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queue
+	s.queue = nil
+
+	return queue
+}
+
+// run delivers the queued events until the subscription is cancelled.
+// The events still queued then are discarded.
+func (s *subscription) run() {
+	for {
+		select {
+		case <-s.ready:
+			for _, event := range s.pop() {
+				select {
+				case <-s.cancelled:
+					return
+				default:
+				}
+
+				deliver(s.subscriber, event)
+			}
+
+		case <-s.cancelled:
+			return
+		}
+	}
+}
+
+// deliver calls a subscriber recovering from its panics: a panicking subscriber is logged
+// and keeps receiving the following events.
+func deliver(subscriber Subscriber, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("event subscriber failed: kind=", event.Kind(), "game=", event.Game(), "error=", r, "\n", string(debug.Stack()))
+		}
+	}()
+
+	subscriber(event)
+}
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDelivery(t *testing.T) {
+	const published = 100
+
+	tests := []struct {
+		name     string
+		delivery Delivery
+		// received is the number of events received out of the published ones, dropped by the bus
+		received, dropped int
+	}{
+		// the first event is being received while the others are published
+		{"latest", DeliverLatest, 1 + 10, published - 1 - 10},
+		{"all", DeliverAll, published, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bus := NewBus(10)
+
+			var received sync.WaitGroup
+			var got []string
+
+			received.Add(test.received)
+
+			// the subscriber is stuck in the first event until every event has been published
+			entered := make(chan struct{}, published)
+			stuck := make(chan struct{})
+
+			cancel := bus.Subscribe(func(e Event) {
+				entered <- struct{}{}
+				<-stuck
+				got = append(got, e.Game())
+				received.Done()
+			}, test.delivery)
+
+			defer cancel()
+
+			for i := 0; i < published; i++ {
+				bus.Publish(&GameCreated{Header: NewHeader(fmt.Sprint(i))})
+
+				if i == 0 {
+					<-entered
+				}
+			}
+
+			close(stuck)
+			received.Wait()
+
+			if len(got) != test.received || int(bus.Dropped()) != test.dropped {
+				t.Errorf("received %d, dropped %d, want %d and %d", len(got), bus.Dropped(), test.received, test.dropped)
+			}
+
+			for i, game := range got {
+				if want := fmt.Sprint(i); game != want {
+					t.Fatalf("event %d of game %s, want %s", i, game, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPanickingSubscriber(t *testing.T) {
+	bus := NewBus(10)
+
+	var received sync.WaitGroup
+
+	received.Add(2)
+
+	cancel := bus.Subscribe(func(e Event) {
+		defer received.Done()
+
+		if e.Game() == "A" {
+			panic("subscriber failure")
+		}
+	}, DeliverAll)
+
+	defer cancel()
+
+	bus.Publish(&GameCreated{Header: NewHeader("A")})
+	bus.Publish(&GameCreated{Header: NewHeader("B")})
+
+	received.Wait()
+}
@@ -0,0 +1,146 @@
+// Package events publishes what happens to the games, eg. players joining or moves,
+// to the subscribers following them: stats, webhooks, metrics, persistence, bots...
+// The players of a game don't depend on it, they are notified by the server directly. See Bus.
+package events
+
+import (
+	"time"
+
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// Kind identifies the type of an event.
+type Kind string
+
+const (
+	// KindGameCreated is the kind of GameCreated events.
+	KindGameCreated Kind = "game_created"
+	// KindPlayerJoined is the kind of PlayerJoined events.
+	KindPlayerJoined Kind = "player_joined"
+	// KindPlayerLeft is the kind of PlayerLeft events.
+	KindPlayerLeft Kind = "player_left"
+	// KindCharacterSelected is the kind of CharacterSelected events.
+	KindCharacterSelected Kind = "character_selected"
+	// KindGameStarted is the kind of GameStarted events.
+	KindGameStarted Kind = "game_started"
+	// KindMoveRecorded is the kind of MoveRecorded events.
+	KindMoveRecorded Kind = "move_recorded"
+	// KindGameEnded is the kind of GameEnded events.
+	KindGameEnded Kind = "game_ended"
+)
+
+// Event is something that happened to a game.
+type Event interface {
+	// Kind returns the type of the event.
+	Kind() Kind
+	// Game returns the id of the game the event happened to.
+	Game() string
+}
+
+// Header holds the fields common to every event.
+type Header struct {
+	GameID string    `json:"game_id"`
+	Time   time.Time `json:"time"`
+}
+
+// NewHeader returns the header of an event happening now.
+func NewHeader(gameID string) Header {
+	return Header{
+		GameID: gameID,
+		Time:   time.Now(),
+	}
+}
+
+// Game returns the id of the game the event happened to.
+func (header Header) Game() string {
+	return header.GameID
+}
+
+// GameCreated is published when a table is created, before its creator joins it.
+// The seed is not included: it is secret until the game ends, see GameEnded.
+type GameCreated struct {
+	Header
+	Variant string     `json:"variant"`
+	Rules   game.Rules `json:"rules"`
+}
+
+// Kind returns KindGameCreated.
+func (*GameCreated) Kind() Kind {
+	return KindGameCreated
+}
+
+// PlayerJoined is published when a user joins a game, or follows it again after a disconnection.
+type PlayerJoined struct {
+	Header
+	PlayerID game.PlayerID `json:"player_id"`
+	User     string        `json:"user"`
+	// Returning is true if the user was already playing the game.
+	Returning bool `json:"returning,omitempty"`
+}
+
+// Kind returns KindPlayerJoined.
+func (*PlayerJoined) Kind() Kind {
+	return KindPlayerJoined
+}
+
+// PlayerLeft is published when the websocket a player was following the game through is closed.
+// The player is still part of the game and can join it back.
+type PlayerLeft struct {
+	Header
+	PlayerID game.PlayerID `json:"player_id"`
+	User     string        `json:"user"`
+}
+
+// Kind returns KindPlayerLeft.
+func (*PlayerLeft) Kind() Kind {
+	return KindPlayerLeft
+}
+
+// CharacterSelected is published when a player picks her/his character.
+type CharacterSelected struct {
+	Header
+	PlayerID  game.PlayerID `json:"player_id"`
+	Character game.Card     `json:"character"`
+}
+
+// Kind returns KindCharacterSelected.
+func (*CharacterSelected) Kind() Kind {
+	return KindCharacterSelected
+}
+
+// GameStarted is published when every player voted to start and the cards have been dealt.
+type GameStarted struct {
+	Header
+	PlayersOrder []game.PlayerID `json:"players_order"`
+}
+
+// Kind returns KindGameStarted.
+func (*GameStarted) Kind() Kind {
+	return KindGameStarted
+}
+
+// MoveRecorded is published for each move of the game, starting from the game.StartMove.
+// The record is the full one: it is not filtered by what a player can see, see game.MoveRecord.AsMessageFor.
+type MoveRecorded struct {
+	Header
+	Record game.MoveRecord `json:"record"`
+}
+
+// Kind returns KindMoveRecorded.
+func (*MoveRecorded) Kind() Kind {
+	return KindMoveRecorded
+}
+
+// GameEnded is published after the move ending the game.
+type GameEnded struct {
+	Header
+	// Winner is the player who declared the solution or, if everyone else failed, the last one left.
+	Winner   game.PlayerID    `json:"winner"`
+	Solution game.Declaration `json:"solution"`
+	Seed     game.Seed        `json:"seed"`
+}
+
+// Kind returns KindGameEnded.
+func (*GameEnded) Kind() Kind {
+	return KindGameEnded
+}
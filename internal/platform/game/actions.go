@@ -0,0 +1,994 @@
+package game
+
+import (
+	"math/rand/v2"
+)
+
+// Action is something a player can do at the table, see Apply.
+type Action interface {
+	// apply enacts the action on the state, which is a clone the action can modify.
+	apply(state *GameState, playerID PlayerID) ([]MoveRecord, error)
+}
+
+// Apply enacts the action of a player and returns the following state, and the records
+// describing what happened. The given state is left untouched, even if the action fails.
+// The records are not timestamped: the same state and action always produce the same result.
+func Apply(state GameState, playerID PlayerID, action Action) (GameState, []MoveRecord, error) {
+	next := state.Clone()
+
+	records, err := action.apply(&next, playerID)
+
+	if err != nil {
+		return state, nil, err
+	}
+
+	return next, records, nil
+}
+
+// SelectCharacterAction assigns a character to the player.
+type SelectCharacterAction struct {
+	Character Card
+}
+
+func (action SelectCharacterAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateStarting {
+		return nil, GameAlreadyStarted
+	}
+
+	if !state.variant.IsCharacter(action.Character) {
+		return nil, NotACharacter
+	}
+
+	i := state.playerIndex(playerID)
+
+	if i < 0 {
+		return nil, NotPlaying
+	}
+
+	player := &state.players[i]
+
+	if player.character == action.Character {
+		return nil, nil
+	}
+
+	for _, cplayer := range state.players {
+		if cplayer.character == action.Character {
+			return nil, AlreadySelected
+		}
+	}
+
+	player.character = action.Character
+
+	return nil, nil
+}
+
+// VoteStartAction records the start vote of the player, see GameState.ReadyToStart.
+type VoteStartAction struct {
+	Vote bool
+}
+
+func (action VoteStartAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateStarting {
+		return nil, GameAlreadyStarted
+	}
+
+	i := state.playerIndex(playerID)
+
+	if i < 0 {
+		return nil, NotPlaying
+	}
+
+	if !state.variant.IsCharacter(state.players[i].character) {
+		return nil, CharacterNotSelected
+	}
+
+	state.players[i].votedStart = action.Vote
+
+	return nil, nil
+}
+
+// StartAction starts the game: players order, solution and deal are drawn.
+// It is enacted by the table, the player id is ignored. It requires at least MinPlayers
+// players, each having selected a character.
+type StartAction struct {
+	// Nonce salts the solution and deal commitments, it has to be unguessable.
+	Nonce string
+}
+
+func (action StartAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateStarting {
+		return nil, GameAlreadyStarted
+	}
+
+	if len(state.players) < MinPlayers {
+		return nil, NotEnoughPlayers
+	}
+
+	for _, player := range state.players {
+		if !state.variant.IsCharacter(player.character) {
+			return nil, CharacterNotSelected
+		}
+	}
+
+	state.nonce = action.Nonce
+
+	state.draw(func(r *rand.Rand) {
+		r.Shuffle(len(state.players), func(i, j int) {
+			state.players[i], state.players[j] = state.players[j], state.players[i]
+		})
+
+		state.state = GameStateNewTurn
+		state.currentPlayer = 0
+
+		// create secret
+
+		state.solution = Declaration{
+			Character: randomCard(r, state.variant.Characters),
+			Room:      randomCard(r, state.variant.Rooms),
+			Weapon:    randomCard(r, state.variant.Weapons),
+		}
+
+		deck := state.makeDeckWithoutSolution()
+
+		r.Shuffle(len(deck), func(i, j int) {
+			deck[i], deck[j] = deck[j], deck[i]
+		})
+
+		cardsPerPlayer := len(deck) / len(state.players)
+		playersWithAnExtraCard := len(deck) % len(state.players)
+		start := 0
+
+		if state.rules.FaceUpLeftovers {
+			state.faceUp = deck[len(deck)-playersWithAnExtraCard:]
+			playersWithAnExtraCard = 0
+		}
+
+		for i := range state.players {
+			cards := cardsPerPlayer
+
+			if i < playersWithAnExtraCard {
+				cards++
+			}
+
+			state.players[i].deck = deck[start : start+cards]
+			start += cards
+		}
+
+		for i := range state.players {
+			player := &state.players[i]
+
+			player.position = state.variant.Board.StartPosition(player.character)
+		}
+
+		state.placeWeapons(r)
+	})
+
+	return nil, nil
+}
+
+// placeWeapons puts each weapon token in a different random room.
+func (state *GameState) placeWeapons(r *rand.Rand) {
+	rooms := append([]Card(nil), state.variant.Rooms...)
+
+	r.Shuffle(len(rooms), func(i, j int) {
+		rooms[i], rooms[j] = rooms[j], rooms[i]
+	})
+
+	state.weapons = make([]WeaponPosition, len(state.variant.Weapons))
+
+	for i, weapon := range state.variant.Weapons {
+		state.weapons[i] = WeaponPosition{
+			Weapon: weapon,
+			Room:   rooms[i],
+		}
+	}
+
+	state.startWeapons = append([]WeaponPosition(nil), state.weapons...)
+}
+
+func randomCard(r *rand.Rand, cards []Card) Card {
+	return cards[r.IntN(len(cards))]
+}
+
+func (state *GameState) makeDeckWithoutSolution() []Card {
+	cards := state.variant.Cards()
+	deck := make([]Card, len(cards)-3)
+
+	p := 0
+
+	for _, c := range cards {
+		if c == state.solution.Character || c == state.solution.Room || c == state.solution.Weapon {
+			continue
+		}
+
+		deck[p] = c
+		p++
+	}
+
+	return deck
+}
+
+// currentPlayerActing returns the current player if she/he is the one acting, NotYourTurn otherwise.
+// The game must have started.
+func (state *GameState) currentPlayerActing(playerID PlayerID) (*PlayerState, error) {
+	player := &state.players[state.currentPlayer]
+
+	if player.id != playerID {
+		return nil, NotYourTurn
+	}
+
+	return player, nil
+}
+
+// RollDicesAction rolls the dices for the current player.
+type RollDicesAction struct{}
+
+func (RollDicesAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateNewTurn {
+		return nil, IllegalState.InStates(GameStateNewTurn)
+	}
+
+	player, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	player.pulled = false
+
+	state.draw(func(r *rand.Rand) {
+		state.dice1 = r.IntN(6) + 1
+		state.dice2 = r.IntN(6) + 1
+	})
+
+	state.remainingSteps = state.dice1 + state.dice2
+	state.path = []PawnPosition{player.position}
+
+	// TODO: cards
+	//if state.dice1 == 1 || state.dice2 == 1 {
+	//	state.state = GameStateCard
+	//} else {
+	state.state = GameStateMove
+	//}
+
+	return []MoveRecord{{
+		PlayerID: player.id,
+		Move: &RollDicesMove{
+			Dice1: state.dice1,
+			Dice2: state.dice2,
+		},
+		StateDelta: StateUpdate{
+			State:          state.state,
+			Dice1:          state.dice1,
+			Dice2:          state.dice2,
+			RemainingSteps: state.remainingSteps,
+		},
+	}}, nil
+}
+
+// UsePassageAction moves the current player through the secret passage of the room she/he is in
+// instead of rolling the dices, then she/he can query the solution in the room on the other side.
+type UsePassageAction struct {
+	Room Card
+}
+
+func (action UsePassageAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateNewTurn {
+		return nil, IllegalState.InStates(GameStateNewTurn)
+	}
+
+	player, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !player.position.InRoom() {
+		return nil, NotInARoom
+	}
+
+	if !state.variant.IsRoom(action.Room) {
+		return nil, NotARoom
+	}
+
+	if !state.IsSecretPassage(player.position.Room, action.Room) {
+		return nil, IllegalMove
+	}
+
+	player.position.EnterRoom(action.Room)
+	player.suggestedIn = NoCard
+	player.pulled = false
+
+	state.state = GameStateQuery
+	state.answeringPlayer = -1
+
+	return []MoveRecord{{
+		PlayerID: player.id,
+		Move: &UsePassageMove{
+			Room: action.Room,
+		},
+		StateDelta: StateUpdate{
+			State: state.state,
+			Positions: []PlayerPosition{
+				{
+					PlayerID:     player.id,
+					PawnPosition: player.position,
+				},
+			},
+		},
+	}}, nil
+}
+
+// StayInRoomAction lets the current player, if a suggestion moved her/his pawn since her/his last turn,
+// query the solution in the room she/he is in without rolling the dices.
+type StayInRoomAction struct{}
+
+func (StayInRoomAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateNewTurn {
+		return nil, IllegalState.InStates(GameStateNewTurn)
+	}
+
+	player, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !player.pulled {
+		return nil, NotPulledBySuggestion
+	}
+
+	player.pulled = false
+
+	state.state = GameStateQuery
+	state.answeringPlayer = -1
+
+	return []MoveRecord{{
+		PlayerID: player.id,
+		Move: &StayInRoomMove{
+			Room: player.position.Room,
+		},
+		StateDelta: StateUpdate{
+			State: state.state,
+		},
+	}}, nil
+}
+
+// MoveAction moves the current player pawn one step: either entering a room, Room set,
+// or to an hallway cell, MapX and MapY set.
+type MoveAction struct {
+	Room Card
+	MapX int
+	MapY int
+}
+
+func (action MoveAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateMove {
+		return nil, IllegalState.InStates(GameStateMove)
+	}
+
+	player, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	room, mapX, mapY := action.Room, action.MapX, action.MapY
+
+	if room != NoCard && !state.variant.IsRoom(room) {
+		return nil, NotARoom
+	}
+
+	var playerPosition PlayerPosition
+	var move2 Move
+
+	if state.variant.IsRoom(room) {
+		if room == player.position.Room {
+			// the player choose to remain in the same room she/he was in
+			state.state = GameStateQuery
+			state.answeringPlayer = -1
+
+			return []MoveRecord{{
+				PlayerID: player.id,
+				Move: &EnterRoomMove{
+					Room: player.position.Room,
+				},
+				StateDelta: StateUpdate{
+					State: state.state,
+					//AnsweringPlayer: PlayerID(state.answeringPlayer),
+				},
+			}}, nil
+		}
+
+		if player.position.InRoom() {
+			// changing room is possible only through secret passages
+
+			if !state.IsSecretPassage(player.position.Room, room) {
+				return nil, IllegalMove
+			}
+
+			player.position.EnterRoom(room)
+			player.suggestedIn = NoCard
+
+			state.state = GameStateQuery
+			state.answeringPlayer = -1
+
+			return []MoveRecord{{
+				PlayerID: player.id,
+				Move: &EnterRoomMove{
+					Room: player.position.Room,
+				},
+				StateDelta: StateUpdate{
+					State: state.state,
+					//AnsweringPlayer: PlayerID(state.answeringPlayer),
+					Positions: []PlayerPosition{
+						{
+							PlayerID:     player.id,
+							PawnPosition: player.position,
+						},
+					},
+				},
+			}}, nil
+		}
+
+		// the player is in the hallway, check if she/he is in front of a door of
+		// the room she/he wants to enter in
+
+		cellType := Card(state.variant.Board.Cell(player.position.MapX, player.position.MapY))
+
+		if cellType != room {
+			return nil, IllegalMove
+		}
+
+		if state.visited(PawnPosition{Room: room}) {
+			// the room the player exited in this turn
+			return nil, AlreadyVisited
+		}
+
+		player.position.EnterRoom(room)
+
+		playerPosition = PlayerPosition{
+			PlayerID:     player.id,
+			PawnPosition: player.position,
+		}
+
+		move2 = &EnterRoomMove{
+			Room: room,
+		}
+
+		state.state = GameStateQuery
+		state.answeringPlayer = -1
+
+	} else if player.position.InRoom() {
+		if !state.IsValidPosition(mapX, mapY) {
+			return nil, IllegalMove
+		}
+
+		// the player just exited a room
+		// check the hallway pos she/he selected is one in front of a door of
+		// the room she/he was in
+		cellType := Card(state.variant.Board.Cell(mapX, mapY))
+
+		if cellType != player.position.Room {
+			return nil, IllegalMove
+		}
+
+		if id := state.IsOccupied(mapX, mapY); id != 0 && id != player.id {
+			return nil, DoorBlocked
+		}
+
+		state.path = append(state.path, PositionAt(mapX, mapY))
+
+		player.position.MoveTo(mapX, mapY)
+		player.suggestedIn = NoCard
+
+		playerPosition = PlayerPosition{
+			PlayerID:     player.id,
+			PawnPosition: player.position,
+		}
+
+		move2 = &MovingInTheHallwayMove{
+			MapX: mapX,
+			MapY: mapY,
+		}
+
+		state.remainingSteps--
+
+		// if the player has just exited a room then this is the first step
+		// no need to check remainingSteps because the min is 2 so it is at least 1
+
+	} else {
+		if !state.IsValidPosition(mapX, mapY) {
+			return nil, IllegalMove
+		}
+
+		if state.variant.Board.Cell(mapX, mapY) < 0 {
+			return nil, IllegalMove
+		}
+
+		if state.IsOccupied(mapX, mapY) != 0 {
+			return nil, IllegalMove
+		}
+
+		if !player.position.IsAdjacent(mapX, mapY) {
+			return nil, IllegalMove
+		}
+
+		if state.visited(PositionAt(mapX, mapY)) {
+			return nil, AlreadyVisited
+		}
+
+		state.path = append(state.path, PositionAt(mapX, mapY))
+
+		player.position.MoveTo(mapX, mapY)
+
+		playerPosition = PlayerPosition{
+			PlayerID:     player.id,
+			PawnPosition: player.position,
+		}
+
+		move2 = &MovingInTheHallwayMove{
+			MapX: mapX,
+			MapY: mapY,
+		}
+
+		state.remainingSteps--
+
+		if state.remainingSteps == 0 {
+			state.state = GameStateTrySolution
+			state.answeringPlayer = -1
+		}
+	}
+
+	var answeringPlayer PlayerID
+	if state.answeringPlayer == -1 {
+		answeringPlayer = 0
+	} else {
+		answeringPlayer = state.players[state.answeringPlayer].id
+	}
+
+	return []MoveRecord{{
+		PlayerID: player.id,
+		Move:     move2,
+		StateDelta: StateUpdate{
+			State:           state.state,
+			AnsweringPlayer: answeringPlayer,
+			RemainingSteps:  state.remainingSteps,
+			Positions: []PlayerPosition{
+				playerPosition,
+			},
+		},
+	}}, nil
+}
+
+// StopMovingAction ends the current player movement in the hallway before using all the steps.
+type StopMovingAction struct{}
+
+func (StopMovingAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateMove {
+		return nil, IllegalState.InStates(GameStateMove)
+	}
+
+	player, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if player.position.InRoom() {
+		// to remain in a room use MoveAction
+		return nil, IllegalMove
+	}
+
+	state.remainingSteps = 0
+	state.state = GameStateTrySolution
+	state.answeringPlayer = -1
+
+	return []MoveRecord{{
+		PlayerID: player.id,
+		Move:     &StopMovingMove{},
+		StateDelta: StateUpdate{
+			State: state.state,
+		},
+	}}, nil
+}
+
+// QuerySolutionAction starts a query solution process in the room the current player is in.
+// The answers, if forced, are resolved too, see SetAutoAnswerAction.
+type QuerySolutionAction struct {
+	Character Card
+	Weapon    Card
+}
+
+func (action QuerySolutionAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateQuery || state.answeringPlayer != -1 {
+		return nil, IllegalState.InStates(GameStateQuery)
+	}
+
+	currentPlayer, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	character, weapon := action.Character, action.Weapon
+
+	room := currentPlayer.position.Room
+	if !state.variant.IsRoom(room) {
+		return nil, NotInARoom
+	}
+
+	if !state.variant.IsCharacter(character) {
+		return nil, NotACharacter
+	}
+
+	if !state.variant.IsWeapon(weapon) {
+		return nil, NotAWeapon
+	}
+
+	if !state.canSuggest(currentPlayer) {
+		return nil, RepeatedRoomSuggestion
+	}
+
+	currentPlayer.suggestedIn = room
+
+	// I need a copy to be referred by MoveRecord
+	// taking &state.query is not an option, history would be modified
+
+	query := Declaration{
+		Character: character,
+		Weapon:    weapon,
+		Room:      room,
+	}
+
+	state.query = query
+	state.answeringPlayer = state.nextAnsweringPlayer(state.currentPlayer)
+
+	var moves []PlayerPosition
+	var pulled []PlayerID
+
+	for i := range state.players {
+		player := &state.players[i]
+
+		if Card(player.character) == character {
+			if player.position.Room == room {
+				break
+			}
+
+			player.position.EnterRoom(room)
+			// being moved by a suggestion allows to suggest again in the room
+			player.suggestedIn = NoCard
+			player.pulled = true
+
+			pulled = append(pulled, player.id)
+
+			moves = append(moves, PlayerPosition{
+				PlayerID:     player.id,
+				PawnPosition: player.position,
+			})
+
+			break
+		}
+	}
+
+	var weapons []WeaponPosition
+
+	for i := range state.weapons {
+		if state.weapons[i].Weapon == weapon && state.weapons[i].Room != room {
+			state.weapons[i].Room = room
+
+			weapons = append(weapons, state.weapons[i])
+		}
+	}
+
+	record := MoveRecord{
+		PlayerID: currentPlayer.id,
+		Move: &QuerySolutionMove{
+			Character: character,
+			Weapon:    weapon,
+		},
+		StateDelta: StateUpdate{
+			State:              state.state,
+			Positions:          moves,
+			Weapons:            weapons,
+			PulledBySuggestion: pulled,
+
+			Query: &query,
+
+			AnsweringPlayer: state.players[state.answeringPlayer].id,
+		},
+	}
+
+	return append([]MoveRecord{record}, state.forcedAnswers()...), nil
+}
+
+// RevealAction answers the query: the answering player shows one of the queried cards,
+// or NoCard if she/he has none of them. The following answers, if forced, are resolved too,
+// see SetAutoAnswerAction.
+type RevealAction struct {
+	Card Card
+}
+
+func (action RevealAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if answering := state.AnsweringPlayer(); answering != 0 && answering != playerID {
+		return nil, NotYourTurn
+	}
+
+	record, err := state.reveal(action.Card)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]MoveRecord{record}, state.forcedAnswers()...), nil
+}
+
+// forcedAnswers reveals on behalf of the answering players that have at most
+// one card to show and whose answers are automatic.
+func (state *GameState) forcedAnswers() []MoveRecord {
+	var records []MoveRecord
+
+	for {
+		if state.AnsweringPlayer() == 0 {
+			return records
+		}
+
+		if !state.rules.AutoAnswer && !state.players[state.answeringPlayer].autoAnswer {
+			return records
+		}
+
+		options := state.AnswerOptions()
+		card := NoCard
+
+		switch len(options) {
+		case 0:
+		case 1:
+			card = options[0]
+		default:
+			return records
+		}
+
+		record, err := state.reveal(card)
+
+		if err != nil {
+			// can't happen: the card is one of the options
+			return records
+		}
+
+		records = append(records, record)
+	}
+}
+
+// SetAutoAnswerAction makes the game answer on behalf of the player when she/he has
+// at most one of the queried cards. If she/he is due to answer such a query
+// the answer is given right away.
+type SetAutoAnswerAction struct {
+	AutoAnswer bool
+}
+
+func (action SetAutoAnswerAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	i := state.playerIndex(playerID)
+
+	if i < 0 {
+		return nil, NotPlaying
+	}
+
+	state.players[i].autoAnswer = action.AutoAnswer
+
+	if !action.AutoAnswer || state.AnsweringPlayer() != playerID {
+		return nil, nil
+	}
+
+	return state.forcedAnswers(), nil
+}
+
+func (state *GameState) reveal(card Card) (MoveRecord, error) {
+	if state.AnsweringPlayer() == 0 {
+		return MoveRecord{}, IllegalState.InStates(GameStateQuery)
+	}
+
+	answeringPlayer := &state.players[state.answeringPlayer]
+
+	if IsCard(card) {
+		if !answeringPlayer.HasCard(card) {
+			return MoveRecord{}, NotYourCard.WithCards(state.AnswerOptions())
+		}
+
+		if card != state.query.Character && card != state.query.Room && card != state.query.Weapon {
+			return MoveRecord{}, CardNotQueried.WithCards(state.AnswerOptions())
+		}
+
+		state.state = GameStateTrySolution
+		state.revealed = true
+		state.revealedCard = card
+
+		return MoveRecord{
+			PlayerID: answeringPlayer.id,
+			Move: &RevealCardMove{
+				Card: card,
+			},
+			StateDelta: StateUpdate{
+				// current player did not change but I need it to decide
+				// if send revealed card or not (only answering and current player see the revealed card)
+				CurrentPlayer: state.players[state.currentPlayer].id,
+				State:         state.state,
+				Revealed:      state.revealed,
+				RevealedCard:  state.revealedCard,
+			},
+		}, nil
+	}
+
+	if options := state.AnswerOptions(); len(options) > 0 {
+		return MoveRecord{}, MustShowACard.WithCards(options)
+	}
+
+	state.answeringPlayer = state.nextAnsweringPlayer(state.answeringPlayer)
+
+	var nextAnsweringPlayerID PlayerID
+
+	if state.answeringPlayer == state.currentPlayer {
+		state.revealed = false
+		state.revealedCard = NoCard
+		state.state = GameStateTrySolution
+
+		nextAnsweringPlayerID = 0
+
+	} else {
+		nextAnsweringPlayerID = state.AnsweringPlayer()
+	}
+
+	return MoveRecord{
+		PlayerID: answeringPlayer.id,
+		Move:     &NoCardToRevealMove{},
+		StateDelta: StateUpdate{
+			State:           state.state,
+			AnsweringPlayer: nextAnsweringPlayerID,
+			Revealed:        state.revealed,
+			RevealedCard:    state.revealedCard,
+		},
+	}, nil
+}
+
+// PassAction skips the query or, after it, the solution declaration ending the turn.
+type PassAction struct{}
+
+func (PassAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	switch state.state {
+	case GameStateQuery:
+		if state.answeringPlayer != -1 {
+			return nil, NotYourTurn
+		}
+
+		player, err := state.currentPlayerActing(playerID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		state.state = GameStateTrySolution
+
+		return []MoveRecord{{
+			PlayerID: player.id,
+			Move:     &PassMove{},
+			StateDelta: StateUpdate{
+				State: state.state,
+			},
+		}}, nil
+
+	case GameStateTrySolution:
+		player, err := state.currentPlayerActing(playerID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		state.state = GameStateNewTurn
+
+		nextPlayer, _ := state.nextTurnPlayer()
+
+		state.currentPlayer = nextPlayer
+
+		state.query = EmptyDeclaration
+
+		state.revealed = false
+		state.revealedCard = NoCard
+
+		return []MoveRecord{{
+			PlayerID: player.id,
+			Move:     &PassMove{},
+			StateDelta: StateUpdate{
+				State:         state.state,
+				CurrentPlayer: state.players[state.currentPlayer].id,
+			},
+		}}, nil
+
+	default:
+		return nil, IllegalState.InStates(GameStateQuery, GameStateTrySolution)
+	}
+}
+
+// DeclareSolutionAction checks the solution declared by the current player: either she/he
+// wins or she/he is out of play. The game ends too if only one player is left.
+type DeclareSolutionAction struct {
+	Character Card
+	Room      Card
+	Weapon    Card
+}
+
+func (action DeclareSolutionAction) apply(state *GameState, playerID PlayerID) ([]MoveRecord, error) {
+	if state.state != GameStateTrySolution {
+		return nil, IllegalState.InStates(GameStateTrySolution)
+	}
+
+	player, err := state.currentPlayerActing(playerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !state.variant.IsCharacter(action.Character) {
+		return nil, NotACharacter
+	}
+
+	if !state.variant.IsRoom(action.Room) {
+		return nil, NotARoom
+	}
+
+	if !state.variant.IsWeapon(action.Weapon) {
+		return nil, NotAWeapon
+	}
+
+	player.declaration = &Declaration{
+		Character: action.Character,
+		Room:      action.Room,
+		Weapon:    action.Weapon,
+	}
+
+	if state.solution == *player.declaration {
+		state.state = GameEnded
+
+		return []MoveRecord{{
+			PlayerID: player.id,
+			Move: &DeclareSolutionMove{
+				Declaration: *player.declaration,
+			},
+			StateDelta: state.endState(),
+		}}, nil
+	}
+
+	nextPlayer, _ := state.nextTurnPlayer()
+
+	state.currentPlayer = nextPlayer
+
+	nextPlayerID := state.players[state.currentPlayer].id
+
+	records := []MoveRecord{{
+		PlayerID: player.id,
+		Move: &DeclareSolutionMove{
+			Declaration: *player.declaration,
+		},
+		StateDelta: StateUpdate{
+			State:         GameStateNewTurn,
+			CurrentPlayer: nextPlayerID,
+		},
+	}}
+
+	// check if nextPlayer is the last one who has not failed to find the solution
+
+	if _, gameEnded := state.nextTurnPlayer(); gameEnded {
+		state.state = GameEnded
+
+		records = append(records, MoveRecord{
+			PlayerID: nextPlayerID,
+			Move: &DeclareSolutionMove{
+				Declaration: state.solution,
+			},
+			StateDelta: state.endState(),
+		})
+
+	} else {
+		state.state = GameStateNewTurn
+	}
+
+	return records, nil
+}
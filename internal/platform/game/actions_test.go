@@ -0,0 +1,691 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// lobbyState returns a classic table with two players that haven't selected a character yet.
+func lobbyState() GameState {
+	state := NewState(Classic, Rules{}, SeedFromID(1))
+
+	state, _, _ = state.AddPlayer()
+	state, _, _ = state.AddPlayer()
+
+	return state
+}
+
+// testState returns a classic game just started: miss Scarlett (1), prof Plum (2) and
+// mrs White (3) play in join order and it is the turn of the first one.
+func testState() GameState {
+	state := NewState(Classic, Rules{}, SeedFromID(1))
+
+	for i, character := range []Card{MissScarlett, ProfPlum, MrsWhite} {
+		state.players = append(state.players, PlayerState{
+			id:         PlayerID(i + 1),
+			character:  character,
+			votedStart: true,
+			deck:       testDecks[i],
+			position:   Classic.Board.StartPosition(character),
+		})
+	}
+
+	state.solution = testSolution
+	state.nonce = "nonce"
+	state.state = GameStateNewTurn
+
+	for i, weapon := range Classic.Weapons {
+		state.weapons = append(state.weapons, WeaponPosition{Weapon: weapon, Room: Classic.Rooms[i]})
+	}
+
+	state.startWeapons = append([]WeaponPosition(nil), state.weapons...)
+
+	return state
+}
+
+// with returns the state modified by f.
+func with(state GameState, f func(state *GameState)) GameState {
+	f(&state)
+
+	return state
+}
+
+// moving returns testState with the first player moving from the given position.
+func moving(position PawnPosition, steps int) GameState {
+	return with(testState(), func(state *GameState) {
+		state.state = GameStateMove
+		state.remainingSteps = steps
+		state.players[0].position = position
+		state.path = []PawnPosition{position}
+	})
+}
+
+// suggesting returns testState with the first player in the room, about to suggest.
+func suggesting(room Card) GameState {
+	return with(testState(), func(state *GameState) {
+		state.state = GameStateQuery
+		state.answeringPlayer = -1
+		state.players[0].position = inRoom(room)
+	})
+}
+
+// answering returns testState with the second player due to answer the query of the first one.
+func answering(query Declaration) GameState {
+	return with(testState(), func(state *GameState) {
+		state.state = GameStateQuery
+		state.query = query
+		state.answeringPlayer = 1
+		state.players[0].position = inRoom(query.Room)
+	})
+}
+
+// trying returns testState with the first player after the query.
+func trying() GameState {
+	return with(testState(), func(state *GameState) {
+		state.state = GameStateTrySolution
+	})
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  GameState
+		player PlayerID
+		action Action
+		err    error
+		check  func(t *testing.T, next GameState, records []MoveRecord)
+	}{
+		{
+			name:   "select character",
+			state:  lobbyState(),
+			player: 1,
+			action: SelectCharacterAction{Character: MrsPeacock},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); player.Character() != MrsPeacock {
+					t.Errorf("character = %v, want %v", player.Character(), MrsPeacock)
+				}
+			},
+		},
+		{
+			name:   "select character already selected",
+			state:  with(lobbyState(), func(state *GameState) { state.players[1].character = MrsPeacock }),
+			player: 1,
+			action: SelectCharacterAction{Character: MrsPeacock},
+			err:    AlreadySelected,
+		},
+		{
+			name:   "select character not a character",
+			state:  lobbyState(),
+			player: 1,
+			action: SelectCharacterAction{Character: Knife},
+			err:    NotACharacter,
+		},
+		{
+			name:   "select character not playing",
+			state:  lobbyState(),
+			player: 9,
+			action: SelectCharacterAction{Character: MrsPeacock},
+			err:    NotPlaying,
+		},
+		{
+			name:   "select character after start",
+			state:  testState(),
+			player: 1,
+			action: SelectCharacterAction{Character: MrsPeacock},
+			err:    GameAlreadyStarted,
+		},
+		{
+			name:   "vote start",
+			state:  with(lobbyState(), func(state *GameState) { state.players[0].character = MrsPeacock }),
+			player: 1,
+			action: VoteStartAction{Vote: true},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); !player.VotedStart() {
+					t.Error("vote not recorded")
+				}
+			},
+		},
+		{
+			name:   "vote start without character",
+			state:  lobbyState(),
+			player: 1,
+			action: VoteStartAction{Vote: true},
+			err:    CharacterNotSelected,
+		},
+		{
+			name:   "vote start after start",
+			state:  testState(),
+			player: 1,
+			action: VoteStartAction{Vote: true},
+			err:    GameAlreadyStarted,
+		},
+		{
+			name: "start",
+			state: with(lobbyState(), func(state *GameState) {
+				state.players[0].character = MrsPeacock
+				state.players[1].character = ColMustard
+			}),
+			action: StartAction{Nonce: "nonce"},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateNewTurn {
+					t.Errorf("state = %v, want %v", next.State(), GameStateNewTurn)
+				}
+
+				// 18 cards left out of the solution
+				for _, deck := range next.Decks() {
+					if len(deck.Deck) != 9 {
+						t.Errorf("player %d has %d cards, want 9", deck.PlayerID, len(deck.Deck))
+					}
+				}
+
+				if player, _ := next.Player(1); player.Position() != Classic.Board.StartPosition(MrsPeacock) {
+					t.Errorf("position = %v, want the start one", player.Position())
+				}
+
+				if len(next.WeaponPositions()) != len(Classic.Weapons) {
+					t.Errorf("%d weapons placed, want %d", len(next.WeaponPositions()), len(Classic.Weapons))
+				}
+			},
+		},
+		{
+			name:   "start without players",
+			state:  NewState(Classic, Rules{}, SeedFromID(1)),
+			action: StartAction{Nonce: "nonce"},
+			err:    NotEnoughPlayers,
+		},
+		{
+			name: "start alone",
+			state: with(NewState(Classic, Rules{}, SeedFromID(1)), func(state *GameState) {
+				state.players = []PlayerState{{id: 1, character: MrsPeacock}}
+			}),
+			action: StartAction{Nonce: "nonce"},
+			err:    NotEnoughPlayers,
+		},
+		{
+			name:   "start without character",
+			state:  with(lobbyState(), func(state *GameState) { state.players[0].character = MrsPeacock }),
+			action: StartAction{Nonce: "nonce"},
+			err:    CharacterNotSelected,
+		},
+		{
+			name:   "start twice",
+			state:  testState(),
+			action: StartAction{Nonce: "nonce"},
+			err:    GameAlreadyStarted,
+		},
+		{
+			name:   "roll dices",
+			state:  testState(),
+			player: 1,
+			action: RollDicesAction{},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateMove {
+					t.Errorf("state = %v, want %v", next.State(), GameStateMove)
+				}
+
+				move := records[0].Move.(*RollDicesMove)
+
+				if next.remainingSteps != move.Dice1+move.Dice2 || move.Dice1 < 1 || move.Dice1 > 6 || move.Dice2 < 1 || move.Dice2 > 6 {
+					t.Errorf("rolled %d %d, remaining steps %d", move.Dice1, move.Dice2, next.remainingSteps)
+				}
+			},
+		},
+		{
+			name:   "roll dices not your turn",
+			state:  testState(),
+			player: 2,
+			action: RollDicesAction{},
+			err:    NotYourTurn,
+		},
+		{
+			name:   "roll dices while moving",
+			state:  moving(PositionAt(7, 23), 3),
+			player: 1,
+			action: RollDicesAction{},
+			err:    IllegalState,
+		},
+		{
+			name:   "use passage",
+			state:  with(testState(), func(state *GameState) { state.players[0].position = inRoom(Kitchen) }),
+			player: 1,
+			action: UsePassageAction{Room: Study},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); player.Position() != inRoom(Study) || next.State() != GameStateQuery {
+					t.Errorf("position = %v, state = %v", player.Position(), next.State())
+				}
+			},
+		},
+		{
+			name:   "use passage in the hallway",
+			state:  testState(),
+			player: 1,
+			action: UsePassageAction{Room: Study},
+			err:    NotInARoom,
+		},
+		{
+			name:   "use passage without passage",
+			state:  with(testState(), func(state *GameState) { state.players[0].position = inRoom(Kitchen) }),
+			player: 1,
+			action: UsePassageAction{Room: Hall},
+			err:    IllegalMove,
+		},
+		{
+			name: "stay in room",
+			state: with(testState(), func(state *GameState) {
+				state.players[0].position = inRoom(Hall)
+				state.players[0].pulled = true
+			}),
+			player: 1,
+			action: StayInRoomAction{},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); player.PulledBySuggestion() || next.State() != GameStateQuery {
+					t.Errorf("pulled = %v, state = %v", player.PulledBySuggestion(), next.State())
+				}
+			},
+		},
+		{
+			name:   "stay in room not pulled",
+			state:  with(testState(), func(state *GameState) { state.players[0].position = inRoom(Hall) }),
+			player: 1,
+			action: StayInRoomAction{},
+			err:    NotPulledBySuggestion,
+		},
+		{
+			name:   "move",
+			state:  moving(PositionAt(7, 24), 3),
+			player: 1,
+			action: MoveAction{MapX: 7, MapY: 23},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); player.Position() != PositionAt(7, 23) || next.remainingSteps != 2 {
+					t.Errorf("position = %v, remaining steps = %d", player.Position(), next.remainingSteps)
+				}
+			},
+		},
+		{
+			name:   "move last step",
+			state:  moving(PositionAt(7, 24), 1),
+			player: 1,
+			action: MoveAction{MapX: 7, MapY: 23},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateTrySolution {
+					t.Errorf("state = %v, want %v", next.State(), GameStateTrySolution)
+				}
+			},
+		},
+		{
+			name:   "move not adjacent",
+			state:  moving(PositionAt(7, 24), 3),
+			player: 1,
+			action: MoveAction{MapX: 7, MapY: 22},
+			err:    IllegalMove,
+		},
+		{
+			name:   "move off the hallway",
+			state:  moving(PositionAt(7, 23), 3),
+			player: 1,
+			action: MoveAction{MapX: 6, MapY: 23},
+			err:    IllegalMove,
+		},
+		{
+			name: "move already visited",
+			state: with(moving(PositionAt(7, 24), 3), func(state *GameState) {
+				state.players[0].position = PositionAt(7, 22)
+				state.path = append(state.path, PositionAt(7, 23), PositionAt(7, 22))
+			}),
+			player: 1,
+			action: MoveAction{MapX: 7, MapY: 23},
+			err:    AlreadyVisited,
+		},
+		{
+			name:   "move before rolling",
+			state:  testState(),
+			player: 1,
+			action: MoveAction{MapX: 7, MapY: 23},
+			err:    IllegalState,
+		},
+		{
+			name:   "enter room",
+			state:  moving(PositionAt(4, 7), 3),
+			player: 1,
+			action: MoveAction{Room: Kitchen},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); player.Position() != inRoom(Kitchen) || next.State() != GameStateQuery {
+					t.Errorf("position = %v, state = %v", player.Position(), next.State())
+				}
+			},
+		},
+		{
+			name:   "enter room far from its door",
+			state:  moving(PositionAt(4, 7), 3),
+			player: 1,
+			action: MoveAction{Room: Hall},
+			err:    IllegalMove,
+		},
+		{
+			name:   "enter room just exited",
+			state:  with(moving(inRoom(Kitchen), 3), func(state *GameState) { state.players[0].position = PositionAt(4, 7) }),
+			player: 1,
+			action: MoveAction{Room: Kitchen},
+			err:    AlreadyVisited,
+		},
+		{
+			name:   "exit room",
+			state:  moving(inRoom(Kitchen), 3),
+			player: 1,
+			action: MoveAction{MapX: 4, MapY: 7},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(1); player.Position() != PositionAt(4, 7) || next.remainingSteps != 2 {
+					t.Errorf("position = %v, remaining steps = %d", player.Position(), next.remainingSteps)
+				}
+			},
+		},
+		{
+			name:   "exit room door blocked",
+			state:  with(moving(inRoom(Kitchen), 3), func(state *GameState) { state.players[1].position = PositionAt(4, 7) }),
+			player: 1,
+			action: MoveAction{MapX: 4, MapY: 7},
+			err:    DoorBlocked,
+		},
+		{
+			name:   "remain in room",
+			state:  moving(inRoom(Kitchen), 3),
+			player: 1,
+			action: MoveAction{Room: Kitchen},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateQuery {
+					t.Errorf("state = %v, want %v", next.State(), GameStateQuery)
+				}
+			},
+		},
+		{
+			name:   "stop moving",
+			state:  moving(PositionAt(7, 23), 3),
+			player: 1,
+			action: StopMovingAction{},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateTrySolution || next.remainingSteps != 0 {
+					t.Errorf("state = %v, remaining steps = %d", next.State(), next.remainingSteps)
+				}
+			},
+		},
+		{
+			name:   "stop moving in a room",
+			state:  moving(inRoom(Kitchen), 3),
+			player: 1,
+			action: StopMovingAction{},
+			err:    IllegalMove,
+		},
+		{
+			name:   "query solution",
+			state:  suggesting(Kitchen),
+			player: 1,
+			action: QuerySolutionAction{Character: ProfPlum, Weapon: Knife},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.AnsweringPlayer() != 2 {
+					t.Errorf("answering player = %d, want 2", next.AnsweringPlayer())
+				}
+
+				if player, _ := next.Player(2); player.Position() != inRoom(Kitchen) || !player.PulledBySuggestion() {
+					t.Errorf("prof Plum position = %v, pulled = %v", player.Position(), player.PulledBySuggestion())
+				}
+
+				if next.Query() != (Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}) {
+					t.Errorf("query = %v", next.Query())
+				}
+			},
+		},
+		{
+			name:   "query solution in the hallway",
+			state:  with(suggesting(Kitchen), func(state *GameState) { state.players[0].position = PositionAt(7, 23) }),
+			player: 1,
+			action: QuerySolutionAction{Character: ProfPlum, Weapon: Knife},
+			err:    NotInARoom,
+		},
+		{
+			name:   "query solution not a character",
+			state:  suggesting(Kitchen),
+			player: 1,
+			action: QuerySolutionAction{Character: Knife, Weapon: Knife},
+			err:    NotACharacter,
+		},
+		{
+			name:   "query solution not a weapon",
+			state:  suggesting(Kitchen),
+			player: 1,
+			action: QuerySolutionAction{Character: ProfPlum, Weapon: Hall},
+			err:    NotAWeapon,
+		},
+		{
+			name: "query solution repeated",
+			state: with(suggesting(Kitchen), func(state *GameState) {
+				state.rules.NoRepeatedRoomSuggestion = true
+				state.players[0].suggestedIn = Kitchen
+			}),
+			player: 1,
+			action: QuerySolutionAction{Character: ProfPlum, Weapon: Knife},
+			err:    RepeatedRoomSuggestion,
+		},
+		{
+			name:   "query solution while answering",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 1,
+			action: QuerySolutionAction{Character: ProfPlum, Weapon: Knife},
+			err:    IllegalState,
+		},
+		{
+			name:   "reveal",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 2,
+			action: RevealAction{Card: ProfPlum},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateTrySolution || next.revealedCard != ProfPlum {
+					t.Errorf("state = %v, revealed card = %v", next.State(), next.revealedCard)
+				}
+			},
+		},
+		{
+			name:   "reveal no card",
+			state:  answering(testSolution),
+			player: 2,
+			action: RevealAction{Card: NoCard},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.AnsweringPlayer() != 3 {
+					t.Errorf("answering player = %d, want 3", next.AnsweringPlayer())
+				}
+			},
+		},
+		{
+			name:   "reveal a card not in the deck",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 2,
+			action: RevealAction{Card: Knife},
+			err:    NotYourCard,
+		},
+		{
+			name:   "reveal a card not queried",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 2,
+			action: RevealAction{Card: LeadPipe},
+			err:    CardNotQueried,
+		},
+		{
+			name:   "reveal no card having one",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 2,
+			action: RevealAction{Card: NoCard},
+			err:    MustShowACard,
+		},
+		{
+			name:   "reveal not your turn",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 3,
+			action: RevealAction{Card: MrsWhite},
+			err:    NotYourTurn,
+		},
+		{
+			name:   "reveal without query",
+			state:  testState(),
+			player: 2,
+			action: RevealAction{Card: ProfPlum},
+			err:    IllegalState,
+		},
+		{
+			name:   "set auto answer",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 3,
+			action: SetAutoAnswerAction{AutoAnswer: true},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if player, _ := next.Player(3); !player.AutoAnswer() || len(records) != 0 {
+					t.Errorf("auto answer = %v, %d records", player.AutoAnswer(), len(records))
+				}
+			},
+		},
+		{
+			name:   "set auto answer when answering",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 2,
+			action: SetAutoAnswerAction{AutoAnswer: true},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if len(records) != 1 || next.revealedCard != ProfPlum {
+					t.Errorf("%d records, revealed card = %v", len(records), next.revealedCard)
+				}
+			},
+		},
+		{
+			name:   "set auto answer not playing",
+			state:  testState(),
+			player: 9,
+			action: SetAutoAnswerAction{AutoAnswer: true},
+			err:    NotPlaying,
+		},
+		{
+			name:   "pass the query",
+			state:  suggesting(Kitchen),
+			player: 1,
+			action: PassAction{},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateTrySolution {
+					t.Errorf("state = %v, want %v", next.State(), GameStateTrySolution)
+				}
+			},
+		},
+		{
+			name:   "pass the turn",
+			state:  trying(),
+			player: 1,
+			action: PassAction{},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateNewTurn || next.CurrentPlayer() != 2 {
+					t.Errorf("state = %v, current player = %d", next.State(), next.CurrentPlayer())
+				}
+			},
+		},
+		{
+			name:   "pass while answering",
+			state:  answering(Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}),
+			player: 1,
+			action: PassAction{},
+			err:    NotYourTurn,
+		},
+		{
+			name:   "pass not your turn",
+			state:  trying(),
+			player: 2,
+			action: PassAction{},
+			err:    NotYourTurn,
+		},
+		{
+			name:   "pass before moving",
+			state:  testState(),
+			player: 1,
+			action: PassAction{},
+			err:    IllegalState,
+		},
+		{
+			name:   "declare the solution",
+			state:  trying(),
+			player: 1,
+			action: DeclareSolutionAction{Character: ColMustard, Room: Kitchen, Weapon: Rope},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameEnded {
+					t.Errorf("state = %v, want %v", next.State(), GameEnded)
+				}
+
+				if end := records[len(records)-1].StateDelta; end.Seed == nil || *end.Solution != testSolution {
+					t.Errorf("end state = %+v", end)
+				}
+			},
+		},
+		{
+			name:   "declare a wrong solution",
+			state:  trying(),
+			player: 1,
+			action: DeclareSolutionAction{Character: ColMustard, Room: Kitchen, Weapon: Knife},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameStateNewTurn || next.CurrentPlayer() != 2 || !next.FailedSolution(1) {
+					t.Errorf("state = %v, current player = %d, failed = %v", next.State(), next.CurrentPlayer(), next.FailedSolution(1))
+				}
+			},
+		},
+		{
+			name: "declare a wrong solution leaving one player",
+			state: with(trying(), func(state *GameState) {
+				state.players[2].declaration = &Declaration{Character: ProfPlum, Room: Hall, Weapon: Knife}
+			}),
+			player: 1,
+			action: DeclareSolutionAction{Character: ColMustard, Room: Kitchen, Weapon: Knife},
+			check: func(t *testing.T, next GameState, records []MoveRecord) {
+				if next.State() != GameEnded || len(records) != 2 || records[1].PlayerID != 2 {
+					t.Errorf("state = %v, %d records", next.State(), len(records))
+				}
+			},
+		},
+		{
+			name:   "declare a solution with a weapon as room",
+			state:  trying(),
+			player: 1,
+			action: DeclareSolutionAction{Character: ColMustard, Room: Rope, Weapon: Rope},
+			err:    NotARoom,
+		},
+		{
+			name:   "declare a solution before moving",
+			state:  testState(),
+			player: 1,
+			action: DeclareSolutionAction{Character: ColMustard, Room: Kitchen, Weapon: Rope},
+			err:    IllegalState,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := test.state.Clone()
+
+			next, records, err := Apply(test.state, test.player, test.action)
+
+			if !reflect.DeepEqual(before, test.state) {
+				t.Error("the given state has been modified")
+			}
+
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("error = %v, want %v", err, test.err)
+				}
+
+				if !reflect.DeepEqual(next, test.state) || records != nil {
+					t.Error("a failed action returned a different state or some records")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if test.check != nil {
+				test.check(t, next, records)
+			}
+		})
+	}
+}
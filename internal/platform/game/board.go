@@ -12,9 +12,62 @@ const (
 	lo = int(Lounge)
 	ha = int(Hall)
 	st = int(Study)
+	ca = int(CarriageHouse)
+	tr = int(TrophyRoom)
+	dr = int(DrawingRoom)
+	ga = int(Gazebo)
+	cy = int(Courtyard)
+	fo = int(Fountain)
+	su = int(Studio)
 )
 
-var clueBoard = [25][24]int{
+// Board is the map pawns move on. Rooms are not mapped: only the hallway cells
+// in front of their doors are.
+type Board struct {
+	cells          [][]int
+	startPositions map[Card]PawnPosition
+	secretPassages [][2]Card
+}
+
+// Width returns the number of columns of the board.
+func (board *Board) Width() int {
+	return len(board.cells[0])
+}
+
+// Height returns the number of rows of the board.
+func (board *Board) Height() int {
+	return len(board.cells)
+}
+
+// IsValidPosition checks if the coords are within the board.
+func (board *Board) IsValidPosition(mapX, mapY int) bool {
+	return mapX >= 0 && mapX < board.Width() && mapY >= 0 && mapY < board.Height()
+}
+
+// Cell returns the content of a board cell: -1 if pawns can't walk on it,
+// 0 if it is a hallway cell and a room card if it is in front of the room door.
+// Coords must be valid, see IsValidPosition.
+func (board *Board) Cell(mapX, mapY int) int {
+	return board.cells[mapY][mapX]
+}
+
+// StartPosition returns where the pawn of a character begins.
+func (board *Board) StartPosition(character Card) PawnPosition {
+	return board.startPositions[character]
+}
+
+// IsSecretPassage checks if there is a secret passage between two rooms.
+func (board *Board) IsSecretPassage(from, to Card) bool {
+	for _, secretPassage := range board.secretPassages {
+		if secretPassage[0] == from && secretPassage[1] == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+var classicCells = [][]int{
 	//0   1   2   3   4   5   6   7   8   9  10  11  12  13  14  15  16  17  18  19  20  21  22  23
 	{xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx}, // 00
 	{xx, xx, xx, xx, xx, xx, xx, oo, oo, oo, xx, xx, xx, xx, oo, oo, oo, xx, xx, xx, xx, xx, xx, xx}, // 01
@@ -44,11 +97,73 @@ var clueBoard = [25][24]int{
 	{xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, oo, xx, xx, xx, xx, xx, xx, xx}, // 24
 }
 
-var initialPositions = map[Card]PawnPosition{
-	MissScarlett: PositionAt(7, 24),
-	RevGreen:     PositionAt(14, 0),
-	ColMustard:   PositionAt(0, 17),
-	ProfPlum:     PositionAt(23, 19),
-	MrsPeacock:   PositionAt(23, 7),
-	MrsWhite:     PositionAt(9, 0),
+var classicBoard = &Board{
+	cells: classicCells,
+	startPositions: map[Card]PawnPosition{
+		MissScarlett: PositionAt(7, 24),
+		RevGreen:     PositionAt(14, 0),
+		ColMustard:   PositionAt(0, 17),
+		ProfPlum:     PositionAt(23, 19),
+		MrsPeacock:   PositionAt(23, 7),
+		MrsWhite:     PositionAt(9, 0),
+	},
+	secretPassages: [][2]Card{
+		{Kitchen, Study},
+		{Study, Kitchen},
+		{Lounge, Conservatory},
+		{Conservatory, Lounge},
+	},
+}
+
+// masterDetectiveCells is an experimental placeholder, not the layout of the Master Detective
+// board: a grid of 12 rooms of the same size, with the doors and the secret passages in
+// regular positions, good enough to play the variant cards until the real board is drawn.
+var masterDetectiveCells = [][]int{
+	//0   1   2   3   4   5   6   7   8   9   10  11  12  13  14  15  16  17  18  19  20  21  22  23  24  25  26  27  28  29
+	{xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx}, // 00
+	{xx, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, xx}, // 01
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 02
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 03
+	{xx, oo, xx, xx, xx, xx, xx, ca, oo, xx, xx, xx, xx, xx, ki, tr, xx, xx, xx, xx, xx, oo, di, xx, xx, xx, xx, xx, oo, xx}, // 04
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 05
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 06
+	{xx, oo, oo, oo, ca, oo, oo, oo, oo, oo, oo, ki, oo, oo, oo, oo, oo, oo, tr, oo, oo, oo, oo, oo, oo, di, oo, oo, oo, xx}, // 07
+	{xx, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, xx}, // 08
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 09
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 10
+	{xx, oo, xx, xx, xx, xx, xx, co, oo, xx, xx, xx, xx, xx, cy, fo, xx, xx, xx, xx, xx, oo, dr, xx, xx, xx, xx, xx, oo, xx}, // 11
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 12
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 13
+	{xx, oo, oo, oo, co, oo, oo, oo, oo, oo, oo, cy, oo, oo, oo, oo, oo, oo, fo, oo, oo, oo, oo, oo, oo, dr, oo, oo, oo, xx}, // 14
+	{xx, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, oo, xx}, // 15
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 16
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 17
+	{xx, oo, xx, xx, xx, xx, xx, ga, oo, xx, xx, xx, xx, xx, li, bi, xx, xx, xx, xx, xx, oo, su, xx, xx, xx, xx, xx, oo, xx}, // 18
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 19
+	{xx, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, oo, xx, xx, xx, xx, xx, oo, xx}, // 20
+	{xx, oo, oo, oo, ga, oo, oo, oo, oo, oo, oo, li, oo, oo, oo, oo, oo, oo, bi, oo, oo, oo, oo, oo, oo, su, oo, oo, oo, xx}, // 21
+	{xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx}, // 22
+}
+
+// masterDetectiveBoard is an experimental placeholder, see masterDetectiveCells.
+var masterDetectiveBoard = &Board{
+	cells: masterDetectiveCells,
+	startPositions: map[Card]PawnPosition{
+		MissScarlett:     PositionAt(11, 22),
+		RevGreen:         PositionAt(11, 0),
+		ColMustard:       PositionAt(0, 11),
+		ProfPlum:         PositionAt(29, 11),
+		MrsPeacock:       PositionAt(25, 0),
+		MrsWhite:         PositionAt(4, 0),
+		MadameRose:       PositionAt(18, 0),
+		SergeantGray:     PositionAt(4, 22),
+		MonsieurBrunette: PositionAt(25, 22),
+		MissPeach:        PositionAt(18, 22),
+	},
+	secretPassages: [][2]Card{
+		{CarriageHouse, Studio},
+		{Studio, CarriageHouse},
+		{Gazebo, DiningRoom},
+		{DiningRoom, Gazebo},
+	},
 }
@@ -52,28 +52,60 @@ const (
 	MrsPeacock
 	// MrsWhite is the Mrs. White card
 	MrsWhite // ITA: Orchid
-)
 
-// Cards is the number of cards in the room+weapon+char deck.
-const Cards = int(MrsWhite)
+	// The following cards belong to the Master Detective variant only.
+	// They are appended so that the classic cards keep their values.
+
+	// Poison is the poison card
+	Poison
+	// Horseshoe is the horseshoe card
+	Horseshoe
+
+	// CarriageHouse is the carriage house card
+	CarriageHouse
+	// TrophyRoom is the trophy room card
+	TrophyRoom
+	// DrawingRoom is the drawing room card
+	DrawingRoom
+	// Gazebo is the gazebo card
+	Gazebo
+	// Courtyard is the courtyard card
+	Courtyard
+	// Fountain is the fountain card
+	Fountain
+	// Studio is the studio card
+	Studio
+
+	// MadameRose is the Madame Rose card
+	MadameRose
+	// SergeantGray is the Sergeant Gray card
+	SergeantGray
+	// MonsieurBrunette is the Monsieur Brunette card
+	MonsieurBrunette
+	// MissPeach is the Miss Peach card
+	MissPeach
+)
 
-// IsRoom returns true if the given card is a room.
+// IsRoom returns true if the given card is a room of any variant.
+// See Variant.IsRoom to check if it belongs to a game.
 func IsRoom(card Card) bool {
-	return Kitchen <= card && card <= Study
+	return Kitchen <= card && card <= Study || CarriageHouse <= card && card <= Studio
 }
 
-// IsWeapon returns true if the given card is a weapon.
+// IsWeapon returns true if the given card is a weapon of any variant.
+// See Variant.IsWeapon to check if it belongs to a game.
 func IsWeapon(card Card) bool {
-	return Candlestick <= card && card <= Wrenck
+	return Candlestick <= card && card <= Wrenck || Poison <= card && card <= Horseshoe
 }
 
-// IsCharacter returns true if the given card is a character.
+// IsCharacter returns true if the given card is a character of any variant.
+// See Variant.IsCharacter to check if it belongs to a game.
 func IsCharacter(card Card) bool {
-	return MissScarlett <= card && card <= MrsWhite
+	return MissScarlett <= card && card <= MrsWhite || MadameRose <= card && card <= MissPeach
 }
 
 // IsCard returns true if the card is valid.
 // Used when casting from int (json).
 func IsCard(card Card) bool {
-	return card >= Candlestick && card <= MrsWhite
+	return card >= Candlestick && card <= MissPeach
 }
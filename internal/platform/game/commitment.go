@@ -0,0 +1,93 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PlayerDeck describes the cards dealt to a player.
+type PlayerDeck struct {
+	PlayerID PlayerID `json:"player_id"`
+	Deck     []Card   `json:"deck"`
+}
+
+// newNonce returns 32 random bytes, hex encoded. It is drawn from crypto/rand because it
+// must not be guessable from the game seed: seeds from SeedFromID are.
+func newNonce() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// CommitSolution returns the hex encoded sha256 of "seed:nonce:character:room:weapon",
+// the seed encoded as by Seed.String and cards named as by Card.String.
+// Eg. "9f86...:1f0c...:miss_scarlett:kitchen:rope".
+// Covering the seed, it proves also that dices and deal have been drawn from the revealed one.
+func CommitSolution(seed Seed, nonce string, solution Declaration) string {
+	return commit(fmt.Sprintf("%s:%s:%s:%s:%s", seed, nonce, solution.Character, solution.Room, solution.Weapon))
+}
+
+// CommitDeal returns the hex encoded sha256 of "seed:nonce" followed, for each deck,
+// by ":player_id=card,card,...", the seed encoded as by Seed.String and cards named as by Card.String.
+// Eg. "9f86...:1f0c...:2=rope,kitchen,plum:1=knife,hall,white".
+func CommitDeal(seed Seed, nonce string, decks []PlayerDeck) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s:%s", seed, nonce)
+
+	for _, d := range decks {
+		names := make([]string, len(d.Deck))
+
+		for i, card := range d.Deck {
+			names[i] = card.String()
+		}
+
+		fmt.Fprintf(&b, ":%d=%s", d.PlayerID, strings.Join(names, ","))
+	}
+
+	return commit(b.String())
+}
+
+func commit(s string) string {
+	h := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(h[:])
+}
+
+// SolutionCommitment returns the commitment of the solution, see CommitSolution.
+// Seed and nonce are revealed when the game ends.
+func (game *Game) SolutionCommitment() string {
+	return CommitSolution(game.state.seed, game.state.nonce, game.state.solution)
+}
+
+// DealCommitment returns the commitment of the players' decks in turn order, see CommitDeal.
+// Seed, nonce and decks are revealed when the game ends.
+func (game *Game) DealCommitment() string {
+	return CommitDeal(game.state.seed, game.state.nonce, game.state.Decks())
+}
+
+// Decks returns the cards dealt to each player, in turn order.
+func (game *Game) Decks() []PlayerDeck {
+	return game.state.Decks()
+}
+
+// Decks returns the cards dealt to each player, in turn order.
+func (state GameState) Decks() []PlayerDeck {
+	decks := make([]PlayerDeck, len(state.players))
+
+	for i, player := range state.players {
+		decks[i] = PlayerDeck{
+			PlayerID: player.id,
+			Deck:     player.deck,
+		}
+	}
+
+	return decks
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestCommitments(t *testing.T) {
+	game := startGame(t, Classic, SeedFromID(7), MissScarlett, ProfPlum, MrsWhite)
+
+	// as published when the game starts
+	solutionCommitment, dealCommitment := game.SolutionCommitment(), game.DealCommitment()
+
+	game.state.state = GameStateTrySolution
+
+	records, err := game.CheckSolution(game.state.solution.Character, game.state.solution.Room, game.state.solution.Weapon)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// what is revealed when the game ends
+	end := records[len(records)-1].StateDelta
+
+	if end.Seed == nil || end.Nonce == "" || end.Solution == nil || len(end.Decks) != 3 {
+		t.Fatalf("end state = %+v, want seed, nonce, solution and decks revealed", end)
+	}
+
+	tests := []struct {
+		name       string
+		commitment string
+		recomputed func() string
+		match      bool
+	}{
+		{"solution", solutionCommitment, func() string {
+			return CommitSolution(*end.Seed, end.Nonce, *end.Solution)
+		}, true},
+		{"deal", dealCommitment, func() string {
+			return CommitDeal(*end.Seed, end.Nonce, end.Decks)
+		}, true},
+		{"other solution", solutionCommitment, func() string {
+			solution := *end.Solution
+			solution.Character, solution.Weapon = solution.Weapon, solution.Character
+
+			return CommitSolution(*end.Seed, end.Nonce, solution)
+		}, false},
+		{"other deal", dealCommitment, func() string {
+			decks := make([]PlayerDeck, len(end.Decks))
+
+			for i, d := range end.Decks {
+				decks[i] = PlayerDeck{PlayerID: d.PlayerID, Deck: append([]Card(nil), d.Deck...)}
+			}
+
+			decks[0].Deck[0], decks[1].Deck[0] = decks[1].Deck[0], decks[0].Deck[0]
+
+			return CommitDeal(*end.Seed, end.Nonce, decks)
+		}, false},
+		{"other nonce", solutionCommitment, func() string {
+			return CommitSolution(*end.Seed, end.Nonce+"0", *end.Solution)
+		}, false},
+		{"other seed", dealCommitment, func() string {
+			return CommitDeal(SeedFromID(8), end.Nonce, end.Decks)
+		}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if match := test.recomputed() == test.commitment; match != test.match {
+				t.Errorf("recomputed commitment matches = %v, want %v", match, test.match)
+			}
+		})
+	}
+}
@@ -8,7 +8,45 @@ func (e Error) Error() string {
 	return string(e)
 }
 
+// InStates returns the error detailed with the states the rejected request is valid in.
+func (e Error) InStates(states ...State) error {
+	return &DetailedError{Err: e, Details: ErrorDetails{States: states}}
+}
+
+// WithCards returns the error detailed with the cards the rejected request could have used.
+func (e Error) WithCards(cards []Card) error {
+	return &DetailedError{Err: e, Details: ErrorDetails{Cards: cards}}
+}
+
+// ErrorDetails help a client to recover from an Error.
+type ErrorDetails struct {
+	// States are the game states the request is valid in.
+	States []State `json:"states,omitempty"`
+	// Cards are the valid cards, eg. the ones that can be revealed.
+	Cards []Card `json:"cards,omitempty"`
+}
+
+// DetailedError is an Error with ErrorDetails.
+// errors.Is(err, e) matches both e and e detailed.
+type DetailedError struct {
+	Err     Error
+	Details ErrorDetails
+}
+
+func (e *DetailedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the Error.
+func (e *DetailedError) Unwrap() error {
+	return e.Err
+}
+
 const (
+	// UnknownRequest error: the request type is not supported.
+	UnknownRequest = Error("unknown_request")
+	// BadRequest error: the request payload can't be decoded.
+	BadRequest = Error("bad_request")
 	// NotSignedIn error: issued for game related requests.
 	NotSignedIn = Error("not_signed_in")
 	// CannotJoinRunningGame error: illegal join game request.
@@ -27,24 +65,48 @@ const (
 	AlreadyPlaying = Error("already_playing")
 	// AlreadySelected error: the choosen character has already been selected.
 	AlreadySelected = Error("already_selected")
-	// NotACharacter error: illegal select char request.
+	// NotACharacter error: the card is not a character of the game variant.
 	NotACharacter = Error("not_a_character")
+	// NotAWeapon error: the card is not a weapon of the game variant.
+	NotAWeapon = Error("not_a_weapon")
+	// NotARoom error: the card is not a room of the game variant.
+	NotARoom = Error("not_a_room")
+	// UnknownVariant error: illegal create game request.
+	UnknownVariant = Error("unknown_variant")
+	// BadRules error: illegal create game request.
+	BadRules = Error("bad_rules")
 	// NotPlaying error: illegal game related request.
 	NotPlaying = Error("not_playing")
 	// GameAlreadyStarted error: illegal game setup request (eg. vote start / select char).
 	GameAlreadyStarted = Error("game_already_started")
-	// CharacterNotSelected error: cannot vote start before having selected a character.
+	// CharacterNotSelected error: cannot vote start, or start, before every player has selected a character.
 	CharacterNotSelected = Error("character_not_selected")
+	// NotEnoughPlayers error: cannot start a game with less than MinPlayers players.
+	NotEnoughPlayers = Error("not_enough_players")
 	// NotYourTurn error: illegal game related request.
 	NotYourTurn = Error("not_your_turn")
 	// IllegalState error: illegal game related request.
 	IllegalState = Error("illegal_state")
 	// IllegalMove error: illegal move parameters.
 	IllegalMove = Error("illegal_move")
+	// AlreadyVisited error: a cell, or the room exited, can be visited only once per turn.
+	AlreadyVisited = Error("already_visited")
+	// DoorBlocked error: another pawn is in front of the door.
+	DoorBlocked = Error("door_blocked")
 	// NotYourCard error: cannot reveal a card not in your deck.
 	NotYourCard = Error("not_your_card")
+	// CardNotQueried error: the revealed card must be one of the queried ones.
+	CardNotQueried = Error("card_not_queried")
 	// MustShowACard error: cannot pass if you have a card to show.
 	MustShowACard = Error("must_show_a_card")
 	// NotInARoom error: cannot query solution if you are not in a room.
 	NotInARoom = Error("not_in_a_room")
+	// NotPulledBySuggestion error: cannot stay in the room without rolling unless moved there by a suggestion.
+	NotPulledBySuggestion = Error("not_pulled_by_suggestion")
+	// RepeatedRoomSuggestion error: the house rules forbid suggesting again in the same room without leaving it.
+	RepeatedRoomSuggestion = Error("repeated_room_suggestion")
+	// TooManyRequests error: the request rate limit has been exceeded.
+	TooManyRequests = Error("too_many_requests")
+	// InternalError error: the server failed to serve the request, the error is logged with an opaque id.
+	InternalError = Error("internal_error")
 )
@@ -0,0 +1,696 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testSolution and testDecks are the secrets of testState: every card is either in the
+// solution or in a deck.
+var (
+	testSolution = Declaration{Character: ColMustard, Room: Kitchen, Weapon: Rope}
+
+	testDecks = [][]Card{
+		{Knife, Hall, Study, RevGreen, Candlestick, Lounge},
+		{LeadPipe, Ballroom, ProfPlum, MrsPeacock, Library, DiningRoom},
+		{Revolver, Wrenck, MissScarlett, MrsWhite, Conservatory, BilliardRoom},
+	}
+)
+
+// testGame returns the game of testState with the given house rules.
+// The weapons are in the rooms in variant order: the candlestick in the kitchen, the knife
+// in the ballroom and so on.
+func testGame(rules Rules) *Game {
+	game := New("TEST", Classic, rules, SeedFromID(1))
+
+	game.state = testState()
+	game.state.rules = rules
+
+	for i := range game.state.players {
+		game.players = append(game.players, &Player{game: game, id: PlayerID(i + 1)})
+	}
+
+	return game
+}
+
+// inRoom returns a PawnPosition in the room.
+func inRoom(room Card) PawnPosition {
+	var position PawnPosition
+
+	position.EnterRoom(room)
+
+	return position
+}
+
+// suggestingIn sets the first player in the room, about to suggest.
+func suggestingIn(game *Game, room Card) {
+	game.state.state = GameStateQuery
+	game.state.answeringPlayer = -1
+	game.state.players[0].position = inRoom(room)
+}
+
+// answeringTo sets prof Plum (2) due to answer the query of the first player.
+func answeringTo(game *Game, query Declaration) {
+	game.state.state = GameStateQuery
+	game.state.query = query
+	game.state.answeringPlayer = 1
+	game.state.players[0].position = inRoom(query.Room)
+}
+
+// movingFrom sets the first player at the position, with steps left to move.
+func movingFrom(game *Game, position PawnPosition, steps int) {
+	game.state.state = GameStateMove
+	game.state.remainingSteps = steps
+	game.state.players[0].position = position
+	game.state.path = []PawnPosition{position}
+}
+
+// startGame returns a game of the variant created from the seed, joined by players with
+// the given characters and started.
+func startGame(t *testing.T, variant *Variant, seed Seed, characters ...Card) *Game {
+	t.Helper()
+
+	game := New("TEST", variant, Rules{}, seed)
+
+	for _, character := range characters {
+		player, err := game.AddPlayer()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := game.SelectCharacter(player, character); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := game.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+// drawn returns what the seed of a game decides: turn order, solution, deal and the first dices.
+func drawn(t *testing.T, game *Game) []interface{} {
+	t.Helper()
+
+	var decks [][]Card
+
+	game.Players(func(player *Player) {
+		decks = append(decks, player.Deck())
+	})
+
+	record, err := game.RollDices()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []interface{}{game.PlayerTurnSequence(), game.state.solution, decks, *record.Move.(*RollDicesMove)}
+}
+
+func TestSeed(t *testing.T) {
+	tests := []struct {
+		name         string
+		seed1, seed2 Seed
+		same         bool
+	}{
+		{"same seed", SeedFromID(7), SeedFromID(7), true},
+		{"different seeds", SeedFromID(7), SeedFromID(8), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := drawn(t, startGame(t, Classic, test.seed1, MissScarlett, ProfPlum, MrsWhite))
+			b := drawn(t, startGame(t, Classic, test.seed2, MissScarlett, ProfPlum, MrsWhite))
+
+			if reflect.DeepEqual(a, b) != test.same {
+				t.Errorf("seeds %v and %v drew %v and %v", test.seed1, test.seed2, a, b)
+			}
+		})
+	}
+}
+
+func TestSeedRevealedAtEnd(t *testing.T) {
+	game := startGame(t, Classic, SeedFromID(7), MissScarlett, ProfPlum)
+
+	if state := game.FullState(game.CurrentPlayer().ID()); state.Seed != nil {
+		t.Error("the seed is revealed before the end")
+	}
+
+	game.state.state = GameStateTrySolution
+
+	records, err := game.CheckSolution(game.state.solution.Character, game.state.solution.Room, game.state.solution.Weapon)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if end := records[len(records)-1].StateDelta; end.Seed == nil || *end.Seed != SeedFromID(7) {
+		t.Errorf("end state = %+v, want the seed revealed", end)
+	}
+}
+
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		variant   *Variant
+		character Card
+		err       error
+	}{
+		{"classic character", Classic, ProfPlum, nil},
+		{"master detective character in classic", Classic, MadameRose, NotACharacter},
+		{"master detective character", masterDetective, MadameRose, nil},
+		{"classic character in master detective", masterDetective, ProfPlum, nil},
+		{"weapon", masterDetective, Horseshoe, NotACharacter},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := New("TEST", test.variant, Rules{}, SeedFromID(7))
+
+			player, err := game.AddPlayer()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := game.SelectCharacter(player, test.character); !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestVariantByName(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant *Variant
+	}{
+		{"", Classic},
+		{ClassicVariant, Classic},
+		// until its board is drawn
+		{masterDetectiveVariant, nil},
+		{"no_such_variant", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if variant, ok := VariantByName(test.name); variant != test.variant || ok != (test.variant != nil) {
+				t.Errorf("variant = %v, %v, want %v", variant, ok, test.variant)
+			}
+		})
+	}
+}
+
+func TestVariantDeal(t *testing.T) {
+	for _, variant := range []*Variant{Classic, masterDetective} {
+		t.Run(variant.Name, func(t *testing.T) {
+			game := startGame(t, variant, SeedFromID(7), MissScarlett, ProfPlum, MrsWhite)
+
+			dealt := []Card{game.state.solution.Character, game.state.solution.Room, game.state.solution.Weapon}
+
+			for _, d := range game.Decks() {
+				dealt = append(dealt, d.Deck...)
+			}
+
+			seen := map[Card]bool{}
+
+			for _, card := range dealt {
+				seen[card] = true
+			}
+
+			if len(dealt) != len(variant.Cards()) || len(seen) != len(dealt) {
+				t.Errorf("%d cards dealt, %d different, want the %d of the variant", len(dealt), len(seen), len(variant.Cards()))
+			}
+
+			for _, card := range variant.Cards() {
+				if !seen[card] {
+					t.Errorf("%v not dealt", card)
+				}
+			}
+
+			if !variant.IsCharacter(game.state.solution.Character) || !variant.IsRoom(game.state.solution.Room) || !variant.IsWeapon(game.state.solution.Weapon) {
+				t.Errorf("solution %v is not made of the variant cards", game.state.solution)
+			}
+		})
+	}
+}
+
+func TestUsePassage(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(game *Game)
+		room  Card
+		err   error
+	}{
+		{"kitchen to study", func(game *Game) { game.state.players[0].position = inRoom(Kitchen) }, Study, nil},
+		{"lounge to conservatory", func(game *Game) { game.state.players[0].position = inRoom(Lounge) }, Conservatory, nil},
+		{"in the hallway", func(game *Game) {}, Study, NotInARoom},
+		{"without passage", func(game *Game) { game.state.players[0].position = inRoom(Kitchen) }, Hall, IllegalMove},
+		{"from a room without passage", func(game *Game) { game.state.players[0].position = inRoom(Hall) }, Study, IllegalMove},
+		{"not a room", func(game *Game) { game.state.players[0].position = inRoom(Kitchen) }, Knife, NotARoom},
+		{"a room of another variant", func(game *Game) { game.state.players[0].position = inRoom(Kitchen) }, Gazebo, NotARoom},
+		{"after rolling", func(game *Game) {
+			game.state.players[0].position = inRoom(Kitchen)
+			game.state.state = GameStateMove
+		}, Study, IllegalState},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(Rules{})
+
+			test.setup(game)
+
+			_, err := game.UsePassage(test.room)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if err == nil && (game.state.players[0].position != inRoom(test.room) || game.state.state != GameStateQuery) {
+				t.Errorf("position = %v, state = %v", game.state.players[0].position, game.state.state)
+			}
+		})
+	}
+}
+
+func TestStayInRoom(t *testing.T) {
+	// pull makes miss Scarlett suggest prof Plum in the kitchen, then passes the turn to prof Plum.
+	pull := func(t *testing.T, game *Game) {
+		suggestingIn(game, Kitchen)
+
+		if _, err := game.QuerySolution(ProfPlum, Knife); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := game.Reveal(ProfPlum); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := game.Pass(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, game *Game)
+		err   error
+	}{
+		{"pulled", pull, nil},
+		{"not pulled", func(t *testing.T, game *Game) {
+			game.state.currentPlayer = 1
+			game.state.players[1].position = inRoom(Kitchen)
+		}, NotPulledBySuggestion},
+		{"pulled last turn", func(t *testing.T, game *Game) {
+			pull(t, game)
+
+			// prof Plum rolls instead of staying
+			if _, err := game.RollDices(); err != nil {
+				t.Fatal(err)
+			}
+
+			game.state.state = GameStateNewTurn
+		}, NotPulledBySuggestion},
+		{"after rolling", func(t *testing.T, game *Game) {
+			pull(t, game)
+
+			if _, err := game.RollDices(); err != nil {
+				t.Fatal(err)
+			}
+		}, IllegalState},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(Rules{})
+
+			test.setup(t, game)
+
+			_, err := game.StayInRoom()
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if player := game.state.players[1]; err == nil && (player.position != inRoom(Kitchen) || player.pulled || game.state.state != GameStateQuery) {
+				t.Errorf("position = %v, pulled = %v, state = %v", player.position, player.pulled, game.state.state)
+			}
+		})
+	}
+}
+
+func TestWeapons(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		game := startGame(t, Classic, SeedFromID(7), MissScarlett, ProfPlum)
+
+		rooms := map[Card]bool{}
+
+		for i, w := range game.WeaponPositions() {
+			if w.Weapon != Classic.Weapons[i] || !Classic.IsRoom(w.Room) || rooms[w.Room] {
+				t.Errorf("weapon tokens placed in %v", game.WeaponPositions())
+			}
+
+			rooms[w.Room] = true
+		}
+
+		if !reflect.DeepEqual(game.StartState().Weapons, game.WeaponPositions()) {
+			t.Errorf("start state weapons = %v, want %v", game.StartState().Weapons, game.WeaponPositions())
+		}
+	})
+
+	tests := []struct {
+		name   string
+		room   Card
+		weapon Card
+		moved  []WeaponPosition
+	}{
+		{"weapon moved", Hall, Knife, []WeaponPosition{{Weapon: Knife, Room: Hall}}},
+		{"weapon already in the room", Ballroom, Knife, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(Rules{})
+
+			suggestingIn(game, test.room)
+
+			records, err := game.QuerySolution(ProfPlum, test.weapon)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if moved := records[0].StateDelta.Weapons; !reflect.DeepEqual(moved, test.moved) {
+				t.Errorf("weapons moved = %v, want %v", moved, test.moved)
+			}
+
+			if w := game.WeaponPositions()[1]; w.Room != test.room {
+				t.Errorf("the knife is in %v, want %v", w.Room, test.room)
+			}
+
+			if w := game.state.startWeapons[1]; w.Room != Ballroom {
+				t.Errorf("the knife started in %v, want %v", w.Room, Ballroom)
+			}
+		})
+	}
+}
+
+func TestReveal(t *testing.T) {
+	// prof Plum has only the first card of the query
+	query := Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}
+
+	tests := []struct {
+		name  string
+		query Declaration
+		card  Card
+		err   error
+		check func(t *testing.T, game *Game)
+	}{
+		{"reveal", query, ProfPlum, nil, func(t *testing.T, game *Game) {
+			if game.state.state != GameStateTrySolution || game.state.revealedCard != ProfPlum {
+				t.Errorf("state = %v, revealed card = %v", game.state.state, game.state.revealedCard)
+			}
+		}},
+		{"no card", testSolution, NoCard, nil, func(t *testing.T, game *Game) {
+			if game.AnsweringPlayer() != game.players[2] {
+				t.Errorf("answering player = %v, want mrs White", game.AnsweringPlayer())
+			}
+		}},
+		{"a card not in the deck", query, Knife, NotYourCard, nil},
+		{"a card not queried", query, LeadPipe, CardNotQueried, nil},
+		{"no card having one", query, NoCard, MustShowACard, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(Rules{})
+
+			answeringTo(game, test.query)
+
+			_, err := game.Reveal(test.card)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if test.check != nil {
+				test.check(t, game)
+			}
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	game := testGame(Rules{})
+
+	answeringTo(game, Declaration{Character: ProfPlum, Room: Library, Weapon: LeadPipe})
+
+	tests := []struct {
+		name    string
+		move    func() error
+		err     Error
+		details ErrorDetails
+	}{
+		{"states", func() error {
+			_, err := game.RollDices()
+			return err
+		}, IllegalState, ErrorDetails{States: []State{GameStateNewTurn}}},
+		{"cards", func() error {
+			_, err := game.Reveal(NoCard)
+			return err
+		}, MustShowACard, ErrorDetails{Cards: []Card{ProfPlum, LeadPipe, Library}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var detailed *DetailedError
+
+			if err := test.move(); !errors.As(err, &detailed) || !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v detailed", err, test.err)
+			}
+
+			if !reflect.DeepEqual(detailed.Details, test.details) {
+				t.Errorf("details = %+v, want %+v", detailed.Details, test.details)
+			}
+		})
+	}
+}
+
+func TestAnswerOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   Declaration
+		options []Card
+	}{
+		{"one card", Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}, []Card{ProfPlum}},
+		{"all the cards", Declaration{Character: ProfPlum, Room: Library, Weapon: LeadPipe}, []Card{ProfPlum, LeadPipe, Library}},
+		{"none", testSolution, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(Rules{})
+
+			answeringTo(game, test.query)
+
+			if options := game.AnswerOptions(); !reflect.DeepEqual(options, test.options) {
+				t.Errorf("options = %v, want %v", options, test.options)
+			}
+		})
+	}
+
+	t.Run("nobody answering", func(t *testing.T) {
+		if options := testGame(Rules{}).AnswerOptions(); options != nil {
+			t.Errorf("options = %v, want none", options)
+		}
+	})
+}
+
+func TestAutoAnswer(t *testing.T) {
+	// prof Plum has one card of the first query, none of the second one, all of the third one
+	oneCard := Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}
+	noCard := Declaration{Character: MissScarlett, Room: Kitchen, Weapon: Knife}
+	allCards := Declaration{Character: ProfPlum, Room: Library, Weapon: LeadPipe}
+
+	tests := []struct {
+		name       string
+		rules      Rules
+		autoAnswer bool
+		query      Declaration
+		// answers are the moves made for the answering players, answering who is left to answer
+		answers   []MoveType
+		answering PlayerID
+	}{
+		{"manual", Rules{}, false, oneCard, nil, 2},
+		{"one card", Rules{}, true, oneCard, []MoveType{RevealCard}, 0},
+		{"no card", Rules{}, true, noCard, []MoveType{NoCardToReveal}, 3},
+		{"a choice", Rules{}, true, allCards, nil, 2},
+		{"table rule", Rules{AutoAnswer: true}, false, oneCard, []MoveType{RevealCard}, 0},
+		{"table rule no card", Rules{AutoAnswer: true}, false, noCard, []MoveType{NoCardToReveal, RevealCard}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(test.rules)
+
+			if _, err := game.SetAutoAnswer(game.players[1], test.autoAnswer); err != nil {
+				t.Fatal(err)
+			}
+
+			suggestingIn(game, test.query.Room)
+
+			records, err := game.QuerySolution(test.query.Character, test.query.Weapon)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var answers []MoveType
+
+			for _, record := range records[1:] {
+				answers = append(answers, record.Move.MoveType())
+			}
+
+			if !reflect.DeepEqual(answers, test.answers) {
+				t.Errorf("answers = %v, want %v", answers, test.answers)
+			}
+
+			answering := PlayerID(0)
+
+			if player := game.AnsweringPlayer(); player != nil {
+				answering = player.ID()
+			}
+
+			if answering != test.answering {
+				t.Errorf("answering player = %d, want %d", answering, test.answering)
+			}
+		})
+	}
+
+	t.Run("set while answering", func(t *testing.T) {
+		game := testGame(Rules{})
+
+		answeringTo(game, oneCard)
+
+		if records, err := game.SetAutoAnswer(game.players[2], true); err != nil || len(records) != 0 {
+			t.Errorf("%d records for a player not answering, error %v", len(records), err)
+		}
+
+		if records, err := game.SetAutoAnswer(game.players[1], true); err != nil || len(records) != 1 || game.state.revealedCard != ProfPlum {
+			t.Errorf("%d records, revealed card = %v, error %v", len(records), game.state.revealedCard, err)
+		}
+	})
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(game *Game)
+		// move is a room or a hallway cell
+		room     Card
+		x, y     int
+		err      error
+		position PawnPosition
+	}{
+		{
+			name:     "step",
+			setup:    func(game *Game) { movingFrom(game, PositionAt(7, 24), 3) },
+			x:        7,
+			y:        23,
+			position: PositionAt(7, 23),
+		},
+		{
+			name: "step back",
+			setup: func(game *Game) {
+				movingFrom(game, PositionAt(7, 24), 3)
+				game.state.players[0].position = PositionAt(7, 22)
+				game.state.path = append(game.state.path, PositionAt(7, 23), PositionAt(7, 22))
+			},
+			x:   7,
+			y:   23,
+			err: AlreadyVisited,
+		},
+		{
+			name:     "exit room",
+			setup:    func(game *Game) { movingFrom(game, inRoom(Kitchen), 3) },
+			x:        4,
+			y:        7,
+			position: PositionAt(4, 7),
+		},
+		{
+			name: "exit room door blocked",
+			setup: func(game *Game) {
+				movingFrom(game, inRoom(Kitchen), 3)
+				game.state.players[1].position = PositionAt(4, 7)
+			},
+			x:   4,
+			y:   7,
+			err: DoorBlocked,
+		},
+		{
+			name:     "enter room",
+			setup:    func(game *Game) { movingFrom(game, PositionAt(4, 7), 3) },
+			room:     Kitchen,
+			position: inRoom(Kitchen),
+		},
+		{
+			name:  "enter a room of another variant",
+			setup: func(game *Game) { movingFrom(game, PositionAt(4, 7), 3) },
+			room:  Gazebo,
+			err:   NotARoom,
+		},
+		{
+			name: "enter room just exited",
+			setup: func(game *Game) {
+				movingFrom(game, inRoom(Kitchen), 3)
+				game.state.players[0].position = PositionAt(4, 7)
+				game.state.path = append(game.state.path, PositionAt(4, 7))
+			},
+			room: Kitchen,
+			err:  AlreadyVisited,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(Rules{})
+
+			test.setup(game)
+
+			_, err := game.Move(test.room, test.x, test.y)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if err == nil && game.state.players[0].position != test.position {
+				t.Errorf("position = %v, want %v", game.state.players[0].position, test.position)
+			}
+		})
+	}
+}
+
+func TestStopMoving(t *testing.T) {
+	game := testGame(Rules{})
+
+	movingFrom(game, inRoom(Kitchen), 3)
+
+	if _, err := game.StopMoving(); !errors.Is(err, IllegalMove) {
+		t.Errorf("stop moving in a room: error = %v, want %v", err, IllegalMove)
+	}
+
+	movingFrom(game, PositionAt(7, 23), 3)
+
+	if _, err := game.StopMoving(); err != nil {
+		t.Fatal(err)
+	}
+
+	if game.state.state != GameStateTrySolution || game.state.remainingSteps != 0 {
+		t.Errorf("state = %v, remaining steps = %d", game.state.state, game.state.remainingSteps)
+	}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 	//"github.com/my_clue_be/internal/platform/web"
 )
@@ -29,6 +30,12 @@ const (
 	// Pass action. Used to skip investigation and solution declaration.
 	// Note: to remain in a room after having rolled the dices, a player use EnterRoom action specifying the same room she/he is in.
 	Pass
+	// UsePassage action: taking a secret passage instead of rolling the dices.
+	UsePassage
+	// StayInRoom action: suggesting without rolling the dices in the room a suggestion moved the pawn in.
+	StayInRoom
+	// StopMoving action: ending the movement in the hallway before using all the steps.
+	StopMoving
 )
 
 // Move is a marker.
@@ -127,6 +134,66 @@ func (move *DeclareSolutionMove) MoveType() MoveType {
 	return DeclareSolution
 }
 
+// UsePassageMove describes a player taking the secret passage to another room.
+type UsePassageMove struct {
+	Room Card `json:"room"`
+}
+
+// MoveType returns UsePassage action.
+func (move *UsePassageMove) MoveType() MoveType {
+	return UsePassage
+}
+
+// StayInRoomMove describes a player, moved by a suggestion, remaining in the room to suggest.
+type StayInRoomMove struct {
+	Room Card `json:"room"`
+}
+
+// MoveType returns StayInRoom action.
+func (move *StayInRoomMove) MoveType() MoveType {
+	return StayInRoom
+}
+
+// StopMovingMove describes a player ending her/his movement in the hallway.
+type StopMovingMove struct{}
+
+// MoveType returns StopMoving action.
+func (move *StopMovingMove) MoveType() MoveType {
+	return StopMoving
+}
+
+// NewMove returns an empty move of the given type, nil if the type is unknown.
+func NewMove(moveType MoveType) Move {
+	switch moveType {
+	case Start:
+		return &StartMove{}
+	case RollDices:
+		return &RollDicesMove{}
+	case MovingInTheHallway:
+		return &MovingInTheHallwayMove{}
+	case EnterRoom:
+		return &EnterRoomMove{}
+	case QuerySolution:
+		return &QuerySolutionMove{}
+	case NoCardToReveal:
+		return &NoCardToRevealMove{}
+	case RevealCard:
+		return &RevealCardMove{}
+	case DeclareSolution:
+		return &DeclareSolutionMove{}
+	case Pass:
+		return &PassMove{}
+	case UsePassage:
+		return &UsePassageMove{}
+	case StayInRoom:
+		return &StayInRoomMove{}
+	case StopMoving:
+		return &StopMovingMove{}
+	default:
+		return nil
+	}
+}
+
 // MoveRecord comprises of the player executing the action, the time she/he did it, which action executed, and its results.
 type MoveRecord struct {
 	PlayerID   PlayerID    `json:"player_id"`
@@ -155,6 +222,12 @@ type PlayerPosition struct {
 	PawnPosition
 }
 
+// WeaponPosition describes the room a weapon token is in.
+type WeaponPosition struct {
+	Weapon Card `json:"weapon"`
+	Room   Card `json:"room"`
+}
+
 // PlayerDeclaration describes a player declaration.
 type PlayerDeclaration struct {
 	PlayerID PlayerID `json:"player_id"`
@@ -171,15 +244,32 @@ type StateUpdate struct {
 	Dice2          int `json:"dice2,omitempty"`
 	RemainingSteps int `json:"remaining_steps,omitempty"`
 
+	// Path are the cells, and the room exited, visited since the dices were rolled:
+	// they can't be visited again in the same turn.
+	Path []PawnPosition `json:"path,omitempty"`
+
 	Positions    []PlayerPosition    `json:"positions,omitempty"`
+	Weapons      []WeaponPosition    `json:"weapons,omitempty"`
 	Declarations []PlayerDeclaration `json:"declarations,omitempty"`
 
+	// PulledBySuggestion are the players whose pawn has been moved by a suggestion
+	// since their last turn, see Game.StayInRoom.
+	PulledBySuggestion []PlayerID `json:"pulled_by_suggestion,omitempty"`
+
 	Query           *Declaration `json:"query,omitempty"`
 	AnsweringPlayer PlayerID     `json:"answering_player,omitempty"`
 	Revealed        bool         `json:"revealed,omitempty"`
 	RevealedCard    Card         `json:"revealed_card,omitempty"`
 
+	// FaceUp are the leftover cards shown to everyone, see Rules.FaceUpLeftovers.
+	FaceUp []Card `json:"face_up,omitempty"`
+
 	Solution *Declaration `json:"solution,omitempty"`
+	// Seed, Nonce and Decks are revealed when the game ends to let clients verify the
+	// commitments received with the game started notification, see CommitSolution.
+	Seed  *Seed        `json:"seed,omitempty"`
+	Nonce string       `json:"nonce,omitempty"`
+	Decks []PlayerDeck `json:"decks,omitempty"`
 }
 
 // AsMessageFor return a record containing only the informations visible by the specified player.
@@ -196,6 +286,10 @@ func (record MoveRecord) AsMessageFor(player *Player) MoveRecord {
 		return record
 	}
 
+	if player.game.state.rules.PublicReveals {
+		return record
+	}
+
 	// otherwise revealed card is not visible to the other players
 	r := record
 
@@ -220,3 +314,34 @@ func (record MoveRecord) MarshalJSON() ([]byte, error) {
 		Type:           record.Move.MoveType(),
 	})
 }
+
+// UnmarshalJSON decodes a json produced by MarshalJSON using the move type to
+// instantiate the right Move.
+func (record *MoveRecord) UnmarshalJSON(b []byte) error {
+	var r struct {
+		JSONMoveRecord
+		Move json.RawMessage `json:"move"`
+		Type MoveType        `json:"type"`
+	}
+
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+
+	move := NewMove(r.Type)
+
+	if move == nil {
+		return fmt.Errorf("unknown move type: %d", r.Type)
+	}
+
+	if len(r.Move) > 0 {
+		if err := json.Unmarshal(r.Move, move); err != nil {
+			return err
+		}
+	}
+
+	*record = MoveRecord(r.JSONMoveRecord)
+	record.Move = move
+
+	return nil
+}
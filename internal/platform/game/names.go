@@ -0,0 +1,141 @@
+package game
+
+import "fmt"
+
+// cardNames are the names of the cards, indexed by Card.
+var cardNames = []string{
+	"no_card",
+	"candlestick",
+	"knife",
+	"lead_pipe",
+	"revolver",
+	"rope",
+	"wrench",
+	"kitchen",
+	"ballroom",
+	"conservatory",
+	"dining_room",
+	"billiard_room",
+	"library",
+	"lounge",
+	"hall",
+	"study",
+	"miss_scarlett",
+	"rev_green",
+	"col_mustard",
+	"prof_plum",
+	"mrs_peacock",
+	"mrs_white",
+	"poison",
+	"horseshoe",
+	"carriage_house",
+	"trophy_room",
+	"drawing_room",
+	"gazebo",
+	"courtyard",
+	"fountain",
+	"studio",
+	"madame_rose",
+	"sergeant_gray",
+	"monsieur_brunette",
+	"miss_peach",
+}
+
+// stateNames are the names of the game states, indexed by State.
+var stateNames = []string{
+	"starting",
+	"new_turn",
+	"card",
+	"move",
+	"query",
+	"try_solution",
+	"ended",
+}
+
+// moveTypeNames are the names of the move types, indexed by MoveType.
+var moveTypeNames = []string{
+	"",
+	"start",
+	"roll_dices",
+	"moving_in_the_hallway",
+	"enter_room",
+	"query_solution",
+	"no_card_to_reveal",
+	"reveal_card",
+	"declare_solution",
+	"pass",
+	"use_passage",
+	"stay_in_room",
+	"stop_moving",
+}
+
+// String returns the card name, eg. lead_pipe.
+func (card Card) String() string {
+	if card < 0 || int(card) >= len(cardNames) {
+		return fmt.Sprintf("card(%d)", int(card))
+	}
+
+	return cardNames[card]
+}
+
+// String returns the state name, eg. new_turn.
+func (state State) String() string {
+	if state < 0 || int(state) >= len(stateNames) {
+		return fmt.Sprintf("state(%d)", int(state))
+	}
+
+	return stateNames[state]
+}
+
+// String returns the move type name, eg. roll_dices.
+func (moveType MoveType) String() string {
+	if moveType <= 0 || int(moveType) >= len(moveTypeNames) {
+		return fmt.Sprintf("move_type(%d)", int(moveType))
+	}
+
+	return moveTypeNames[moveType]
+}
+
+// ParseCard returns the card with the given name.
+func ParseCard(name string) (Card, bool) {
+	for i, n := range cardNames {
+		if n == name {
+			return Card(i), true
+		}
+	}
+
+	return NoCard, false
+}
+
+// AllCards returns all the cards, NoCard included.
+func AllCards() []Card {
+	r := make([]Card, len(cardNames))
+
+	for i := range cardNames {
+		r[i] = Card(i)
+	}
+
+	return r
+}
+
+// AllStates returns all the game states.
+func AllStates() []State {
+	r := make([]State, len(stateNames))
+
+	for i := range stateNames {
+		r[i] = State(i)
+	}
+
+	return r
+}
+
+// AllMoveTypes returns all the move types.
+func AllMoveTypes() []MoveType {
+	r := make([]MoveType, 0, len(moveTypeNames)-1)
+
+	for i := 1; i < len(moveTypeNames); i++ {
+		r = append(r, MoveType(i))
+	}
+
+	return r
+}
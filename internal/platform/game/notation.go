@@ -0,0 +1,570 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The game notation is a text format describing a whole game, eg.
+//
+//	[Game "K3ZQ"]
+//	[Variant "classic"]
+//	[Seed "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"]
+//	[Players "prof_plum mrs_white col_mustard"]
+//	[Order "2 3 1"]
+//	[Solution "miss_scarlett kitchen rope"]
+//	[Deal "2=knife,hall,study 3=wrench,lounge,rev_green 1=..."]
+//
+//	2 roll 3 4
+//	2 move 8 23
+//	...
+//	2 enter lounge
+//	2 suggest prof_plum knife # in the lounge
+//	3 noshow
+//	1 show lead_pipe
+//	2 pass
+//	2 accuse col_mustard hall revolver # wrong
+//
+// Headers come first, then one line per move record: the id of the acting player,
+// a verb and its arguments. Players are listed in join order, so the first one
+// has id 1, by their character or ? if they have not chosen one yet. Cards are
+// named as by Card.String, # starts a comment.
+//
+// Verbs are:
+//
+//	roll DICE1 DICE2
+//	move X Y                       a step in the hallway
+//	stop                           ending the movement in the hallway
+//	enter ROOM                     entering or remaining in a room
+//	passage ROOM                   taking the secret passage instead of rolling
+//	stay                           suggesting without rolling after being moved by a suggestion
+//	suggest CHARACTER WEAPON       the room is the one the player is in
+//	noshow                         the answering player has none of the cards
+//	show CARD                      CARD may be ? if unknown
+//	pass
+//	accuse CHARACTER ROOM WEAPON
+//
+// A missing Variant header stands for the classic variant, a missing Rules
+// header for the default rules, see Rules.String.
+// Since the seed determines order, deal and dices, the notation is enough to
+// rebuild the game with the engine, see ReadNotation. Solution, Deal and FaceUp
+// headers are checked against the rebuilt game if present. FaceUp lists the
+// leftover cards dealt face up, see Rules.FaceUpLeftovers.
+// The notation reveals every secret of the game, seed included.
+
+// NotationError describes a malformed or illegal line of a game notation.
+type NotationError struct {
+	Line int
+	Err  error
+}
+
+func (e *NotationError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// WriteNotation writes the game in notation format.
+func (game *Game) WriteNotation(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	characters := make([]string, len(game.players))
+
+	for _, player := range game.players {
+		if character := player.Character(); character != NoCard {
+			characters[player.id-1] = character.String()
+		} else {
+			characters[player.id-1] = "?"
+		}
+	}
+
+	writeHeader(bw, "Game", game.gameID)
+	writeHeader(bw, "Variant", game.state.variant.Name)
+
+	if rules := game.state.rules.String(); rules != "" {
+		writeHeader(bw, "Rules", rules)
+	}
+
+	writeHeader(bw, "Seed", game.state.seed.String())
+	writeHeader(bw, "Players", strings.Join(characters, " "))
+
+	if game.Started() {
+		writeHeader(bw, "Order", formatPlayerIDs(game.PlayerTurnSequence()))
+		writeHeader(bw, "Solution", fmt.Sprintf("%s %s %s", game.state.solution.Character, game.state.solution.Room, game.state.solution.Weapon))
+		writeHeader(bw, "Deal", formatDecks(game.Decks()))
+
+		if len(game.state.faceUp) > 0 {
+			writeHeader(bw, "FaceUp", formatCards(game.state.faceUp))
+		}
+	}
+
+	if len(game.history) > 0 {
+		bw.WriteString("\n")
+	}
+
+	for i, record := range game.history {
+		bw.WriteString(formatRecord(record))
+
+		switch move := record.Move.(type) {
+		case *QuerySolutionMove:
+			if record.StateDelta.Query != nil {
+				fmt.Fprintf(bw, " # in the %s", record.StateDelta.Query.Room)
+			}
+
+		case *StayInRoomMove:
+			fmt.Fprintf(bw, " # in the %s", move.Room)
+
+		case *DeclareSolutionMove:
+			switch {
+			case record.StateDelta.State != GameEnded:
+				bw.WriteString(" # wrong")
+			case i > 0 && game.history[i-1].Move.MoveType() == DeclareSolution:
+				bw.WriteString(" # last player left")
+			default:
+				bw.WriteString(" # right")
+			}
+		}
+
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w *bufio.Writer, key, value string) {
+	fmt.Fprintf(w, "[%s %s]\n", key, strconv.Quote(value))
+}
+
+func formatPlayerIDs(ids []PlayerID) string {
+	s := make([]string, len(ids))
+
+	for i, id := range ids {
+		s[i] = strconv.Itoa(int(id))
+	}
+
+	return strings.Join(s, " ")
+}
+
+func formatDecks(decks []PlayerDeck) string {
+	s := make([]string, len(decks))
+
+	for i, d := range decks {
+		s[i] = fmt.Sprintf("%d=%s", d.PlayerID, formatCards(d.Deck))
+	}
+
+	return strings.Join(s, " ")
+}
+
+func formatCards(cards []Card) string {
+	names := make([]string, len(cards))
+
+	for i, card := range cards {
+		names[i] = card.String()
+	}
+
+	return strings.Join(names, ",")
+}
+
+// formatRecord returns the notation line of a record, without comments.
+func formatRecord(record *MoveRecord) string {
+	var action string
+
+	switch move := record.Move.(type) {
+	case *RollDicesMove:
+		action = fmt.Sprintf("roll %d %d", move.Dice1, move.Dice2)
+	case *MovingInTheHallwayMove:
+		action = fmt.Sprintf("move %d %d", move.MapX, move.MapY)
+	case *EnterRoomMove:
+		action = fmt.Sprintf("enter %s", move.Room)
+	case *UsePassageMove:
+		action = fmt.Sprintf("passage %s", move.Room)
+	case *StayInRoomMove:
+		action = "stay"
+	case *StopMovingMove:
+		action = "stop"
+	case *QuerySolutionMove:
+		action = fmt.Sprintf("suggest %s %s", move.Character, move.Weapon)
+	case *NoCardToRevealMove:
+		action = "noshow"
+	case *RevealCardMove:
+		if IsCard(move.Card) {
+			action = fmt.Sprintf("show %s", move.Card)
+		} else {
+			action = "show ?"
+		}
+	case *PassMove:
+		action = "pass"
+	case *DeclareSolutionMove:
+		action = fmt.Sprintf("accuse %s %s %s", move.Character, move.Room, move.Weapon)
+	default:
+		action = record.Move.MoveType().String()
+	}
+
+	return fmt.Sprintf("%d %s", record.PlayerID, action)
+}
+
+// ReadNotation rebuilds a game from its notation replaying every move with the engine.
+// A partial game, eg. a puzzle, is rebuilt up to its last move.
+func ReadNotation(r io.Reader) (*Game, error) {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	headers := make(map[string]string)
+	var game *Game
+
+	// pending are the records the engine produced on its own, eg. the victory
+	// of the last player left, still to be matched with the following lines
+	var pending []*MoveRecord
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Text()
+
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if game != nil {
+				return nil, &NotationError{lineNumber, fmt.Errorf("header after the moves")}
+			}
+
+			key, value, err := parseHeader(line)
+
+			if err != nil {
+				return nil, &NotationError{lineNumber, err}
+			}
+
+			headers[key] = value
+			continue
+		}
+
+		if game == nil {
+			var err error
+
+			if game, err = setupFromHeaders(headers); err != nil {
+				return nil, &NotationError{lineNumber, err}
+			}
+		}
+
+		player, verb, args, err := parseMoveLine(line)
+
+		if err != nil {
+			return nil, &NotationError{lineNumber, err}
+		}
+
+		if len(pending) > 0 {
+			record := pending[0]
+			pending = pending[1:]
+
+			if formatRecord(record) != formatLine(player, verb, args) {
+				return nil, &NotationError{lineNumber, fmt.Errorf("expected %q", formatRecord(record))}
+			}
+
+			continue
+		}
+
+		records, err := game.replay(player, verb, args)
+
+		if err != nil {
+			return nil, &NotationError{lineNumber, err}
+		}
+
+		pending = records[1:]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if game == nil {
+		var err error
+
+		if game, err = setupFromHeaders(headers); err != nil {
+			return nil, &NotationError{lineNumber, err}
+		}
+	}
+
+	if len(pending) > 0 {
+		return nil, &NotationError{lineNumber, fmt.Errorf("missing line %q", formatRecord(pending[0]))}
+	}
+
+	return game, nil
+}
+
+func parseHeader(line string) (string, string, error) {
+	if !strings.HasSuffix(line, "]") {
+		return "", "", fmt.Errorf("malformed header")
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(line[1:len(line)-1]), " ", 2)
+
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("malformed header")
+	}
+
+	value, err := strconv.Unquote(strings.TrimSpace(fields[1]))
+
+	if err != nil {
+		return "", "", fmt.Errorf("malformed header value: %v", err)
+	}
+
+	return fields[0], value, nil
+}
+
+// setupFromHeaders creates and starts the game described by the headers,
+// checking that it matches the recorded order, solution and deal.
+func setupFromHeaders(headers map[string]string) (*Game, error) {
+	seed, err := ParseSeed(headers["Seed"])
+
+	if err != nil {
+		return nil, fmt.Errorf("missing or malformed Seed header")
+	}
+
+	variant, ok := VariantByName(headers["Variant"])
+
+	if !ok {
+		return nil, UnknownVariant
+	}
+
+	rules, err := ParseRules(headers["Rules"])
+
+	if err != nil {
+		return nil, err
+	}
+
+	game := New(headers["Game"], variant, rules, seed)
+
+	for _, name := range strings.Fields(headers["Players"]) {
+		player, err := game.AddPlayer()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "?" {
+			// not chosen yet
+			continue
+		}
+
+		character, ok := ParseCard(name)
+
+		if !ok || character == NoCard {
+			return nil, fmt.Errorf("unknown character %s", name)
+		}
+
+		if _, err := game.SelectCharacter(player, character); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := headers["Order"]; !ok {
+		// the game has not started
+		return game, nil
+	}
+
+	if len(game.players) < 2 {
+		return nil, fmt.Errorf("not enough players")
+	}
+
+	if err := game.Start(); err != nil {
+		return nil, err
+	}
+
+	if order := formatPlayerIDs(game.PlayerTurnSequence()); order != strings.Join(strings.Fields(headers["Order"]), " ") {
+		return nil, fmt.Errorf("seed produces order %s", order)
+	}
+
+	if solution, ok := headers["Solution"]; ok {
+		expected := fmt.Sprintf("%s %s %s", game.state.solution.Character, game.state.solution.Room, game.state.solution.Weapon)
+
+		if strings.Join(strings.Fields(solution), " ") != expected {
+			return nil, fmt.Errorf("seed produces solution %s", expected)
+		}
+	}
+
+	if deal, ok := headers["Deal"]; ok {
+		if expected := formatDecks(game.Decks()); strings.Join(strings.Fields(deal), " ") != expected {
+			return nil, fmt.Errorf("seed produces deal %s", expected)
+		}
+	}
+
+	if faceUp, ok := headers["FaceUp"]; ok && strings.TrimSpace(faceUp) != formatCards(game.state.faceUp) {
+		return nil, fmt.Errorf("seed produces face up cards %s", formatCards(game.state.faceUp))
+	}
+
+	return game, nil
+}
+
+func parseMoveLine(line string) (PlayerID, string, []string, error) {
+	fields := strings.Fields(line)
+
+	if len(fields) < 2 {
+		return 0, "", nil, fmt.Errorf("malformed move")
+	}
+
+	id, err := strconv.Atoi(fields[0])
+
+	if err != nil {
+		return 0, "", nil, fmt.Errorf("malformed player id %s", fields[0])
+	}
+
+	return PlayerID(id), fields[1], fields[2:], nil
+}
+
+func formatLine(player PlayerID, verb string, args []string) string {
+	return strings.Join(append([]string{strconv.Itoa(int(player)), verb}, args...), " ")
+}
+
+// actingPlayer returns the player the game is waiting for.
+func (game *Game) actingPlayer() *Player {
+	if answering := game.AnsweringPlayer(); answering != nil {
+		return answering
+	}
+
+	return game.CurrentPlayer()
+}
+
+// replay enacts a notation line and returns the records produced by the engine.
+func (game *Game) replay(player PlayerID, verb string, args []string) ([]*MoveRecord, error) {
+	if !game.Started() {
+		return nil, fmt.Errorf("the game has not started")
+	}
+
+	if game.state.state == GameEnded {
+		return nil, fmt.Errorf("the game has ended")
+	}
+
+	if acting := game.actingPlayer(); acting.id != player {
+		return nil, fmt.Errorf("expected a move of player %d", acting.id)
+	}
+
+	cards, numbers, err := parseArgs(verb, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var record *MoveRecord
+
+	switch verb {
+	case "roll":
+		if record, err = game.RollDices(); err != nil {
+			return nil, err
+		}
+
+		if rolled := record.Move.(*RollDicesMove); rolled.Dice1 != numbers[0] || rolled.Dice2 != numbers[1] {
+			return nil, fmt.Errorf("seed rolls %d %d", rolled.Dice1, rolled.Dice2)
+		}
+
+	case "move":
+		record, err = game.Move(NoCard, numbers[0], numbers[1])
+
+	case "enter":
+		record, err = game.Move(cards[0], 0, 0)
+
+	case "passage":
+		record, err = game.UsePassage(cards[0])
+
+	case "stay":
+		record, err = game.StayInRoom()
+
+	case "stop":
+		record, err = game.StopMoving()
+
+	case "suggest":
+		return game.QuerySolution(cards[0], cards[1])
+
+	case "noshow":
+		return game.Reveal(NoCard)
+
+	case "show":
+		card := cards[0]
+
+		if card == NoCard {
+			// unknown card: any of the queried ones will do
+			options := game.AnswerOptions()
+
+			if len(options) == 0 {
+				return nil, MustShowACard
+			}
+
+			card = options[0]
+		}
+
+		return game.Reveal(card)
+
+	case "pass":
+		record, err = game.Pass()
+
+	case "accuse":
+		return game.CheckSolution(cards[0], cards[1], cards[2])
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []*MoveRecord{record}, nil
+}
+
+// parseArgs validates the arguments of a verb, returning them as cards or numbers.
+func parseArgs(verb string, args []string) ([]Card, []int, error) {
+	var cardArgs, numberArgs int
+
+	switch verb {
+	case "roll", "move":
+		numberArgs = 2
+	case "enter", "passage", "show":
+		cardArgs = 1
+	case "suggest":
+		cardArgs = 2
+	case "accuse":
+		cardArgs = 3
+	case "noshow", "pass", "stay", "stop":
+	default:
+		return nil, nil, fmt.Errorf("unknown verb %s", verb)
+	}
+
+	if len(args) != cardArgs+numberArgs {
+		return nil, nil, fmt.Errorf("%s requires %d arguments", verb, cardArgs+numberArgs)
+	}
+
+	var cards []Card
+	var numbers []int
+
+	for _, arg := range args[:cardArgs] {
+		if verb == "show" && arg == "?" {
+			cards = append(cards, NoCard)
+			continue
+		}
+
+		card, ok := ParseCard(arg)
+
+		if !ok || card == NoCard {
+			return nil, nil, fmt.Errorf("unknown card %s", arg)
+		}
+
+		cards = append(cards, card)
+	}
+
+	for _, arg := range args[cardArgs:] {
+		n, err := strconv.Atoi(arg)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("malformed number %s", arg)
+		}
+
+		numbers = append(numbers, n)
+	}
+
+	return cards, numbers, nil
+}
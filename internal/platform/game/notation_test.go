@@ -0,0 +1,233 @@
+package game
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// playRandomly plays legal moves chosen by r for the given number of steps, or until
+// the game ends. From the turns-th turn on, players declare the solution, sometimes a wrong one.
+func playRandomly(t *testing.T, game *Game, r *rand.Rand, steps, turns int) {
+	t.Helper()
+
+	for step, turn := 0, 0; step < steps && game.state.state != GameEnded; step++ {
+		var err error
+
+		switch game.state.state {
+		case GameStateNewTurn:
+			turn++
+
+			player := game.state.players[game.state.currentPlayer]
+			position := player.position
+
+			switch {
+			case player.pulled && r.IntN(2) == 0:
+				_, err = game.StayInRoom()
+			case position.InRoom() && r.IntN(2) == 0 && passageFrom(game, position.Room) != NoCard:
+				_, err = game.UsePassage(passageFrom(game, position.Room))
+			default:
+				_, err = game.RollDices()
+			}
+
+		case GameStateMove:
+			if room, x, y, ok := randomStep(game, r); ok {
+				_, err = game.Move(room, x, y)
+			} else {
+				_, err = game.StopMoving()
+			}
+
+		case GameStateQuery:
+			if game.AnsweringPlayer() != nil {
+				options := game.AnswerOptions()
+				card := NoCard
+
+				if len(options) > 0 {
+					card = options[r.IntN(len(options))]
+				}
+
+				_, err = game.Reveal(card)
+
+			} else if game.CurrentPlayer().CanSuggest() {
+				_, err = game.QuerySolution(randomCard(r, game.state.variant.Characters), randomCard(r, game.state.variant.Weapons))
+
+			} else {
+				_, err = game.Pass()
+			}
+
+		case GameStateTrySolution:
+			if turn >= turns {
+				solution := game.state.solution
+
+				if r.IntN(3) == 0 {
+					// a wrong guess
+					solution.Weapon = randomCard(r, game.state.variant.Weapons)
+				}
+
+				_, err = game.CheckSolution(solution.Character, solution.Room, solution.Weapon)
+			} else {
+				_, err = game.Pass()
+			}
+		}
+
+		if err != nil {
+			t.Fatalf("step %d: %v", step, err)
+		}
+	}
+}
+
+// passageFrom returns the room the secret passage of room leads to, NoCard if none.
+func passageFrom(game *Game, room Card) Card {
+	for _, to := range game.state.variant.Rooms {
+		if game.IsSecretPassage(room, to) {
+			return to
+		}
+	}
+
+	return NoCard
+}
+
+// randomStep returns the arguments of a legal move: remaining in the room, taking its secret
+// passage or exiting it, entering the room in front of the pawn or a step in the hallway.
+// It returns false if the pawn is in the hallway and should stop.
+func randomStep(game *Game, r *rand.Rand) (Card, int, int, bool) {
+	position := game.state.players[game.state.currentPlayer].position
+	board := game.state.variant.Board
+
+	var candidates [][2]int
+
+	if position.InRoom() {
+		if passage := passageFrom(game, position.Room); passage != NoCard && r.IntN(3) == 0 {
+			return passage, 0, 0, true
+		}
+
+		for y := 0; y < board.Height(); y++ {
+			for x := 0; x < board.Width(); x++ {
+				if Card(board.Cell(x, y)) == position.Room && game.IsOccupied(x, y) == nil {
+					candidates = append(candidates, [2]int{x, y})
+				}
+			}
+		}
+
+		if len(candidates) == 0 || r.IntN(4) == 0 {
+			return position.Room, 0, 0, true
+		}
+
+	} else {
+		if room := Card(board.Cell(position.MapX, position.MapY)); IsRoom(room) && !game.state.visited(PawnPosition{Room: room}) && r.IntN(2) == 0 {
+			return room, 0, 0, true
+		}
+
+		for _, d := range [][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}} {
+			x, y := position.MapX+d[0], position.MapY+d[1]
+
+			if game.IsValidPosition(x, y) && board.Cell(x, y) >= 0 && game.IsOccupied(x, y) == nil && !game.state.visited(PositionAt(x, y)) {
+				candidates = append(candidates, [2]int{x, y})
+			}
+		}
+
+		if len(candidates) == 0 || r.IntN(10) == 0 {
+			return NoCard, 0, 0, false
+		}
+	}
+
+	c := candidates[r.IntN(len(candidates))]
+
+	return NoCard, c[0], c[1], true
+}
+
+// snapshot returns what a rebuilt game must have in common with the original one.
+func snapshot(game *Game) []interface{} {
+	return []interface{}{
+		game.state.state, game.state.currentPlayer, game.state.answeringPlayer, game.state.remainingSteps, game.state.query,
+		game.state.solution, game.PlayerTurnSequence(), game.Decks(), game.PlayerPositions(), len(game.history),
+	}
+}
+
+func TestNotationRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant *Variant
+		rules   Rules
+		players []Card
+		// steps is the number of moves played, turns the turn the players start declaring the solution
+		steps, turns int
+		ended        bool
+	}{
+		{"not started", Classic, Rules{}, []Card{MissScarlett, ProfPlum}, -1, 0, false},
+		{"no character", Classic, Rules{}, []Card{MissScarlett, NoCard, ProfPlum}, -1, 0, false},
+		{"just started", Classic, Rules{}, []Card{MissScarlett, ProfPlum}, 0, 0, false},
+		{"classic", Classic, Rules{}, []Card{MissScarlett, ProfPlum, MrsWhite}, 1000, 30, true},
+		{"partial", Classic, Rules{}, []Card{MissScarlett, ProfPlum, MrsWhite}, 150, 30, false},
+		{"first accusation", Classic, Rules{}, []Card{MissScarlett, RevGreen}, 1000, 1, true},
+		{"house rules", Classic, Rules{
+			AnsweringOrder:           Counterclockwise,
+			SkipEliminatedPlayers:    true,
+			FaceUpLeftovers:          true,
+			NoRepeatedRoomSuggestion: true,
+			PublicReveals:            true,
+			AutoAnswer:               true,
+		}, []Card{MissScarlett, ProfPlum, MrsWhite, ColMustard}, 1000, 30, true},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := New("TEST", test.variant, test.rules, SeedFromID(int64(i)))
+
+			for _, character := range test.players {
+				player, err := game.AddPlayer()
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if character == NoCard {
+					continue
+				}
+
+				if _, err := game.SelectCharacter(player, character); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if test.steps >= 0 {
+				if err := game.Start(); err != nil {
+					t.Fatal(err)
+				}
+
+				playRandomly(t, game, rand.New(rand.NewPCG(uint64(i), 0)), test.steps, test.turns)
+			}
+
+			if ended := game.state.state == GameEnded; ended != test.ended {
+				t.Fatalf("ended = %v after %d moves", ended, len(game.history))
+			}
+
+			var notation bytes.Buffer
+
+			if err := game.WriteNotation(&notation); err != nil {
+				t.Fatal(err)
+			}
+
+			rebuilt, err := ReadNotation(bytes.NewReader(notation.Bytes()))
+
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, notation.String())
+			}
+
+			var again bytes.Buffer
+
+			if err := rebuilt.WriteNotation(&again); err != nil {
+				t.Fatal(err)
+			}
+
+			if again.String() != notation.String() {
+				t.Errorf("notation\n%s\nrebuilt as\n%s", notation.String(), again.String())
+			}
+
+			if !reflect.DeepEqual(snapshot(rebuilt), snapshot(game)) {
+				t.Error("the rebuilt game is in a different state")
+			}
+		})
+	}
+}
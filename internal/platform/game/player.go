@@ -58,19 +58,11 @@ func (position PawnPosition) IsAdjacent(x1, y1 int) bool {
 // A custom type helps to avoid ambiguity and signal mismatch when using the wrong id.
 type PlayerID int
 
-// Player collects all playing user data.
+// Player is a player of a Game. Her/his state is the one of the current GameState of the game.
 // A user can play multiple games simultaneously.
 type Player struct {
 	game *Game
 	id   PlayerID
-	// character is a valid character Card only when the user select an available character.
-	character Card
-
-	votedStart bool
-
-	deck []Card
-
-	position PawnPosition
 
 	// UserIO is defined if the user is connected, nil otherwise.
 	// Because UserIO is a websocket, this one-to-one binding limits to one tab per game.
@@ -78,8 +70,6 @@ type Player struct {
 
 	// User is a link to user's infos when user is offline and UserIO is nil
 	//User *web.User
-
-	declaration *Declaration
 }
 
 // ID returns the player id.
@@ -92,29 +82,42 @@ func (player *Player) Game() *Game {
 	return player.game
 }
 
+// State returns the player state in the current game state.
+func (player *Player) State() PlayerState {
+	state, _ := player.game.state.Player(player.id)
+
+	return state
+}
+
 // Character returns the character the player is.
 func (player *Player) Character() Card {
-	return player.character
+	return player.State().Character()
 }
 
 // Deck returns the player deck.
 // FIXME: this function breaks "private" fields design: deck is a pointer and can be modified
 func (player *Player) Deck() []Card {
-	return player.deck
+	return player.State().Deck()
 }
 
 // FailedSolution return wether the player is in play or not.
 func (player *Player) FailedSolution() bool {
-	return player.declaration != nil && *player.declaration != player.game.solution
+	return player.game.state.FailedSolution(player.id)
+}
+
+// CanSuggest returns false if the house rules forbid the player to suggest in the room she/he is in
+// because she/he already did without leaving it.
+func (player *Player) CanSuggest() bool {
+	return player.game.state.CanSuggest(player.id)
+}
+
+// PulledBySuggestion returns true if a suggestion moved the pawn in a room since the player's
+// last turn: on her/his next turn she/he may suggest there without rolling the dices.
+func (player *Player) PulledBySuggestion() bool {
+	return player.State().PulledBySuggestion()
 }
 
 // HasCard checks if the player has the card in her/his deck.
 func (player *Player) HasCard(card Card) bool {
-	for _, c := range player.deck {
-		if c == card {
-			return true
-		}
-	}
-
-	return false
+	return player.State().HasCard(card)
 }
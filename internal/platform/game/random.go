@@ -0,0 +1,12 @@
+package game
+
+import (
+	"math/rand/v2"
+)
+
+// draw calls f with a rand.Rand following the values the state has already drawn from its seed.
+// The generator is part of the state: game states drawing the same values from the same seed
+// get the same dices, deal and so on, whatever the other states have drawn meanwhile.
+func (state *GameState) draw(f func(r *rand.Rand)) {
+	f(rand.New(&state.random))
+}
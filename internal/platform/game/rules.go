@@ -0,0 +1,123 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnsweringOrder is the direction the players answer a suggestion in.
+type AnsweringOrder string
+
+const (
+	// Clockwise is the default answering order: the next player in turn order answers first.
+	Clockwise AnsweringOrder = "clockwise"
+	// Counterclockwise makes the previous player in turn order answer first.
+	Counterclockwise AnsweringOrder = "counterclockwise"
+)
+
+// Rules are the house rules chosen when a game is created.
+// The zero value is the default rule set.
+type Rules struct {
+	// AnsweringOrder defaults to Clockwise.
+	AnsweringOrder AnsweringOrder `json:"answering_order,omitempty"`
+	// SkipEliminatedPlayers makes the players that failed their accusation
+	// stop answering suggestions.
+	SkipEliminatedPlayers bool `json:"skip_eliminated_players,omitempty"`
+	// FaceUpLeftovers deals the same number of cards to every player, the
+	// leftover ones are shown to everyone.
+	FaceUpLeftovers bool `json:"face_up_leftovers,omitempty"`
+	// NoRepeatedRoomSuggestion forbids suggesting again in the room of the
+	// previous suggestion without having left it.
+	NoRepeatedRoomSuggestion bool `json:"no_repeated_room_suggestion,omitempty"`
+	// PublicReveals shows the revealed cards to every player, not just to the suggester.
+	PublicReveals bool `json:"public_reveals,omitempty"`
+	// AutoAnswer makes the game answer for every player that has at most one
+	// card to show, see Game.SetAutoAnswer for the per player preference.
+	AutoAnswer bool `json:"auto_answer,omitempty"`
+}
+
+// rule names used by Rules.String and ParseRules.
+const (
+	skipEliminatedPlayersRule    = "skip_eliminated_players"
+	faceUpLeftoversRule          = "face_up_leftovers"
+	noRepeatedRoomSuggestionRule = "no_repeated_room_suggestion"
+	publicRevealsRule            = "public_reveals"
+	autoAnswerRule               = "auto_answer"
+)
+
+// Validate checks the rule values.
+func (rules Rules) Validate() error {
+	switch rules.AnsweringOrder {
+	case "", Clockwise, Counterclockwise:
+		return nil
+	default:
+		return BadRules
+	}
+}
+
+// String returns the non default rules separated by spaces, eg.
+// "counterclockwise public_reveals". See ParseRules.
+func (rules Rules) String() string {
+	var r []string
+
+	if rules.AnsweringOrder != "" && rules.AnsweringOrder != Clockwise {
+		r = append(r, string(rules.AnsweringOrder))
+	}
+
+	if rules.SkipEliminatedPlayers {
+		r = append(r, skipEliminatedPlayersRule)
+	}
+
+	if rules.FaceUpLeftovers {
+		r = append(r, faceUpLeftoversRule)
+	}
+
+	if rules.NoRepeatedRoomSuggestion {
+		r = append(r, noRepeatedRoomSuggestionRule)
+	}
+
+	if rules.PublicReveals {
+		r = append(r, publicRevealsRule)
+	}
+
+	if rules.AutoAnswer {
+		r = append(r, autoAnswerRule)
+	}
+
+	return strings.Join(r, " ")
+}
+
+// ParseRules parses the output of Rules.String.
+func ParseRules(s string) (Rules, error) {
+	rules := Rules{}
+
+	for _, name := range strings.Fields(s) {
+		if !rules.Set(name) {
+			return Rules{}, fmt.Errorf("unknown rule %s", name)
+		}
+	}
+
+	return rules, nil
+}
+
+// Set enables a rule by name, as in Rules.String. It returns false if the name is unknown.
+func (rules *Rules) Set(name string) bool {
+	switch name {
+	case string(Clockwise), string(Counterclockwise):
+		rules.AnsweringOrder = AnsweringOrder(name)
+	case skipEliminatedPlayersRule:
+		rules.SkipEliminatedPlayers = true
+	case faceUpLeftoversRule:
+		rules.FaceUpLeftovers = true
+	case noRepeatedRoomSuggestionRule:
+		rules.NoRepeatedRoomSuggestion = true
+	case publicRevealsRule:
+		rules.PublicReveals = true
+	case autoAnswerRule:
+		rules.AutoAnswer = true
+	default:
+		return false
+	}
+
+	return true
+}
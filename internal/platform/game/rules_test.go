@@ -0,0 +1,214 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	// a query prof Plum (2) can answer
+	query := Declaration{Character: ProfPlum, Room: Kitchen, Weapon: Knife}
+
+	// answeringPlayer returns the id of the player due to answer the query.
+	answeringPlayer := func(t *testing.T, game *Game) PlayerID {
+		suggestingIn(game, query.Room)
+
+		if _, err := game.QuerySolution(query.Character, query.Weapon); err != nil {
+			t.Fatal(err)
+		}
+
+		return game.AnsweringPlayer().ID()
+	}
+
+	// revealedTo returns the card revealed by prof Plum as seen by mrs White (3).
+	revealedTo := func(t *testing.T, game *Game) Card {
+		if answering := answeringPlayer(t, game); answering != 2 {
+			t.Fatalf("answering player = %d, want 2", answering)
+		}
+
+		records, err := game.Reveal(ProfPlum)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return records[0].AsMessageFor(game.players[2]).StateDelta.RevealedCard
+	}
+
+	// eliminate makes prof Plum fail an accusation.
+	eliminate := func(game *Game) {
+		game.state.players[1].declaration = &Declaration{Character: MrsWhite, Room: Hall, Weapon: Knife}
+	}
+
+	tests := []struct {
+		name  string
+		rules Rules
+		setup func(game *Game)
+		check func(t *testing.T, game *Game)
+	}{
+		{"clockwise", Rules{}, nil, func(t *testing.T, game *Game) {
+			if answering := answeringPlayer(t, game); answering != 2 {
+				t.Errorf("answering player = %d, want 2", answering)
+			}
+		}},
+		{"counterclockwise", Rules{AnsweringOrder: Counterclockwise}, nil, func(t *testing.T, game *Game) {
+			if answering := answeringPlayer(t, game); answering != 3 {
+				t.Errorf("answering player = %d, want 3", answering)
+			}
+		}},
+		{"eliminated players answer", Rules{}, eliminate, func(t *testing.T, game *Game) {
+			if answering := answeringPlayer(t, game); answering != 2 {
+				t.Errorf("answering player = %d, want 2", answering)
+			}
+		}},
+		{"skip eliminated players", Rules{SkipEliminatedPlayers: true}, eliminate, func(t *testing.T, game *Game) {
+			if answering := answeringPlayer(t, game); answering != 3 {
+				t.Errorf("answering player = %d, want 3", answering)
+			}
+		}},
+		{"skip eliminated players counterclockwise", Rules{AnsweringOrder: Counterclockwise, SkipEliminatedPlayers: true}, func(game *Game) {
+			game.state.players[2].declaration = &Declaration{Character: ProfPlum, Room: Hall, Weapon: Knife}
+		}, func(t *testing.T, game *Game) {
+			if answering := answeringPlayer(t, game); answering != 2 {
+				t.Errorf("answering player = %d, want 2", answering)
+			}
+		}},
+		{"private reveals", Rules{}, nil, func(t *testing.T, game *Game) {
+			if card := revealedTo(t, game); card != NoCard {
+				t.Errorf("mrs White sees %v, want no card", card)
+			}
+		}},
+		{"public reveals", Rules{PublicReveals: true}, nil, func(t *testing.T, game *Game) {
+			if card := revealedTo(t, game); card != ProfPlum {
+				t.Errorf("mrs White sees %v, want %v", card, ProfPlum)
+			}
+		}},
+		{"repeated room suggestion", Rules{}, func(game *Game) {
+			game.state.players[0].suggestedIn = Kitchen
+		}, func(t *testing.T, game *Game) {
+			suggestingIn(game, Kitchen)
+
+			if _, err := game.QuerySolution(ProfPlum, Knife); err != nil {
+				t.Errorf("error = %v, want none", err)
+			}
+		}},
+		{"no repeated room suggestion", Rules{NoRepeatedRoomSuggestion: true}, func(game *Game) {
+			game.state.players[0].suggestedIn = Kitchen
+		}, func(t *testing.T, game *Game) {
+			suggestingIn(game, Kitchen)
+
+			if _, err := game.QuerySolution(ProfPlum, Knife); !errors.Is(err, RepeatedRoomSuggestion) {
+				t.Errorf("error = %v, want %v", err, RepeatedRoomSuggestion)
+			}
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := testGame(test.rules)
+
+			if test.setup != nil {
+				test.setup(game)
+			}
+
+			test.check(t, game)
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		s     string
+	}{
+		{"default", Rules{}, ""},
+		{"clockwise", Rules{AnsweringOrder: Clockwise}, ""},
+		{"all", Rules{
+			AnsweringOrder:           Counterclockwise,
+			SkipEliminatedPlayers:    true,
+			FaceUpLeftovers:          true,
+			NoRepeatedRoomSuggestion: true,
+			PublicReveals:            true,
+			AutoAnswer:               true,
+		}, "counterclockwise skip_eliminated_players face_up_leftovers no_repeated_room_suggestion public_reveals auto_answer"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if s := test.rules.String(); s != test.s {
+				t.Errorf("String() = %q, want %q", s, test.s)
+			}
+
+			rules, err := ParseRules(test.s)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rules.String() != test.s {
+				t.Errorf("ParseRules(%q) = %+v", test.s, rules)
+			}
+		})
+	}
+
+	if _, err := ParseRules("no_such_rule"); err == nil {
+		t.Error("an unknown rule has been parsed")
+	}
+}
+
+func TestFaceUpLeftovers(t *testing.T) {
+	players := []Card{MissScarlett, ProfPlum, MrsWhite, ColMustard, MrsPeacock, RevGreen}
+
+	// the classic deck has 18 cards left out of the solution
+	tests := []struct {
+		name    string
+		players int
+		rules   Rules
+		faceUp  int
+		decks   []int
+	}{
+		{"3 players", 3, Rules{}, 0, []int{6, 6, 6}},
+		{"4 players", 4, Rules{}, 0, []int{5, 5, 4, 4}},
+		{"3 players face up", 3, Rules{FaceUpLeftovers: true}, 0, []int{6, 6, 6}},
+		{"4 players face up", 4, Rules{FaceUpLeftovers: true}, 2, []int{4, 4, 4, 4}},
+		{"5 players face up", 5, Rules{FaceUpLeftovers: true}, 3, []int{3, 3, 3, 3, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := New("TEST", Classic, test.rules, SeedFromID(7))
+
+			for _, character := range players[:test.players] {
+				player, _ := game.AddPlayer()
+
+				if _, err := game.SelectCharacter(player, character); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := game.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(game.FaceUpCards()) != test.faceUp {
+				t.Errorf("%d cards face up, want %d", len(game.FaceUpCards()), test.faceUp)
+			}
+
+			for i, d := range game.Decks() {
+				if len(d.Deck) != test.decks[i] {
+					t.Errorf("player %d has %d cards, want %d", d.PlayerID, len(d.Deck), test.decks[i])
+				}
+			}
+
+			if faceUp := game.FullState(1).FaceUp; !reflect.DeepEqual(faceUp, game.FaceUpCards()) {
+				t.Errorf("full state face up cards = %v, want %v", faceUp, game.FaceUpCards())
+			}
+
+			if faceUp := game.StartState().FaceUp; !reflect.DeepEqual(faceUp, game.FaceUpCards()) {
+				t.Errorf("start state face up cards = %v, want %v", faceUp, game.FaceUpCards())
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// Seed is what player order, solution, deal and dices of a game are drawn from, see GameState.draw.
+// It has to be kept secret until the game ends: the solution can be worked out from it.
+// It is encoded in json and notation as 64 hex digits.
+type Seed [32]byte
+
+// NewSeed returns an unguessable seed drawn from crypto/rand.
+func NewSeed() (Seed, error) {
+	var seed Seed
+
+	if _, err := rand.Read(seed[:]); err != nil {
+		return Seed{}, err
+	}
+
+	return seed, nil
+}
+
+// SeedFromID returns the seed of a reproducible game: the same id always gives the same game.
+// Ids are few enough to be tried all, so anyone knowing how the game started can work out its
+// solution: they suit tests, simulations and replays, not games played for real.
+func SeedFromID(id int64) Seed {
+	return sha256.Sum256([]byte("my_clue_be seed " + strconv.FormatInt(id, 10)))
+}
+
+// ParseSeed decodes a seed from its 64 hex digits.
+func ParseSeed(s string) (Seed, error) {
+	var seed Seed
+
+	b, err := hex.DecodeString(s)
+
+	if err != nil || len(b) != len(seed) {
+		return Seed{}, fmt.Errorf("malformed seed %s", s)
+	}
+
+	copy(seed[:], b)
+
+	return seed, nil
+}
+
+func (seed Seed) String() string {
+	return hex.EncodeToString(seed[:])
+}
+
+// MarshalText encodes the seed as 64 hex digits.
+func (seed Seed) MarshalText() ([]byte, error) {
+	return []byte(seed.String()), nil
+}
+
+// UnmarshalText decodes a seed encoded by MarshalText.
+func (seed *Seed) UnmarshalText(text []byte) error {
+	s, err := ParseSeed(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*seed = s
+
+	return nil
+}
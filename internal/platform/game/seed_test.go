@@ -0,0 +1,46 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSeedText(t *testing.T) {
+	seed := SeedFromID(1)
+
+	b, err := json.Marshal(seed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Seed
+
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded != seed {
+		t.Errorf("%s decoded as %v, error %v", b, decoded, err)
+	}
+
+	for _, s := range []string{"", "xyz", seed.String()[2:], seed.String() + "00"} {
+		if _, err := ParseSeed(s); err == nil {
+			t.Errorf("%q parsed", s)
+		}
+	}
+}
+
+func TestNewSeed(t *testing.T) {
+	a, err := NewSeed()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewSeed()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a == b || a == (Seed{}) {
+		t.Errorf("seeds %v and %v", a, b)
+	}
+}
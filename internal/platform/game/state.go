@@ -0,0 +1,511 @@
+package game
+
+import (
+	"math/rand/v2"
+)
+
+// GameState is the state of a clue table as a value: actions are applied to it by Apply,
+// which returns a new state leaving the given one untouched. Keeping the states lets bots
+// search the moves ahead and replays step back and forth.
+type GameState struct {
+	variant *Variant
+	rules   Rules
+	seed    Seed
+
+	// random is the generator seeded with seed, see draw.
+	random rand.ChaCha8
+
+	// players are in join order until the game starts, in turn order afterwards.
+	players []PlayerState
+
+	solution Declaration
+	// faceUp are the leftover cards shown to everyone, see Rules.FaceUpLeftovers
+	faceUp []Card
+	// weapons are the weapon tokens, in the variant order
+	weapons []WeaponPosition
+	// startWeapons are the weapon tokens as placed at start
+	startWeapons []WeaponPosition
+	// nonce salts the solution and deal commitments
+	nonce string
+
+	state           State
+	currentPlayer   int
+	dice1           int
+	dice2           int
+	remainingSteps  int
+	query           Declaration
+	answeringPlayer int
+
+	// path are the cells, and the room exited, visited since the dices were rolled
+	path []PawnPosition
+
+	revealed     bool
+	revealedCard Card
+}
+
+// PlayerState is the state of a player in a GameState.
+type PlayerState struct {
+	id PlayerID
+	// character is a valid character Card only when the user select an available character.
+	character Card
+
+	votedStart bool
+
+	deck []Card
+
+	position PawnPosition
+	// suggestedIn is the room of the last suggestion, until the player leaves it.
+	suggestedIn Card
+	// autoAnswer makes the game answer for the player when she/he has at most one card to show
+	autoAnswer bool
+	// pulled is true if a suggestion moved the pawn in a room since the player's last turn.
+	pulled bool
+
+	declaration *Declaration
+}
+
+// NewState returns the state of a table without players. Player order, solution, deal and dices
+// are drawn from the seed: same variant, rules, seed and actions produce the same game.
+func NewState(variant *Variant, rules Rules, seed Seed) GameState {
+	return GameState{
+		variant: variant,
+		rules:   rules,
+		seed:    seed,
+		random:  *rand.NewChaCha8(seed),
+
+		state: GameStateStarting,
+	}
+}
+
+// Clone returns a deep copy of the state: modifying one doesn't affect the other.
+// Only the variant is shared, it is never modified.
+func (state GameState) Clone() GameState {
+	state.players = append([]PlayerState(nil), state.players...)
+
+	for i := range state.players {
+		state.players[i] = state.players[i].clone()
+	}
+
+	state.faceUp = append([]Card(nil), state.faceUp...)
+	state.weapons = append([]WeaponPosition(nil), state.weapons...)
+	state.startWeapons = append([]WeaponPosition(nil), state.startWeapons...)
+	state.path = append([]PawnPosition(nil), state.path...)
+
+	return state
+}
+
+func (player PlayerState) clone() PlayerState {
+	player.deck = append([]Card(nil), player.deck...)
+
+	if player.declaration != nil {
+		declaration := *player.declaration
+		player.declaration = &declaration
+	}
+
+	return player
+}
+
+// AddPlayer returns the state with a new player at the table, and her/his id.
+func (state GameState) AddPlayer() (GameState, PlayerID, error) {
+	if state.state != GameStateStarting {
+		return state, 0, CannotJoinRunningGame
+	}
+
+	if len(state.players) == state.variant.MaxPlayers {
+		return state, 0, TableIsFull
+	}
+
+	id := PlayerID(len(state.players) + 1)
+
+	next := state.Clone()
+	next.players = append(next.players, PlayerState{id: id})
+
+	return next, id, nil
+}
+
+// Variant returns the variant of the game.
+func (state GameState) Variant() *Variant {
+	return state.variant
+}
+
+// Rules returns the house rules of the game.
+func (state GameState) Rules() Rules {
+	return state.rules
+}
+
+// Seed returns the seed the game has been created with.
+// It has to be kept secret until the game ends.
+func (state GameState) Seed() Seed {
+	return state.seed
+}
+
+// Started return true if the game has started.
+func (state GameState) Started() bool {
+	return state.state != GameStateStarting
+}
+
+// State returns the game state.
+func (state GameState) State() State {
+	return state.state
+}
+
+// FaceUpCards returns the leftover cards shown to everyone.
+func (state GameState) FaceUpCards() []Card {
+	return state.faceUp
+}
+
+// WeaponPositions returns the rooms the weapon tokens are in.
+func (state GameState) WeaponPositions() []WeaponPosition {
+	return append([]WeaponPosition(nil), state.weapons...)
+}
+
+// Players returns the players, in turn order once the game has started.
+func (state GameState) Players() []PlayerState {
+	return append([]PlayerState(nil), state.players...)
+}
+
+// Player returns the player with the given id, false if there is none.
+func (state GameState) Player(id PlayerID) (PlayerState, bool) {
+	i := state.playerIndex(id)
+
+	if i < 0 {
+		return PlayerState{}, false
+	}
+
+	return state.players[i], true
+}
+
+// playerIndex returns the index of the player in the players slice, -1 if unknown.
+func (state *GameState) playerIndex(id PlayerID) int {
+	for i := range state.players {
+		if state.players[i].id == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// CurrentPlayer returns the id of the current player, 0 if the game has not started.
+func (state GameState) CurrentPlayer() PlayerID {
+	if state.state == GameStateStarting {
+		return 0
+	}
+
+	return state.players[state.currentPlayer].id
+}
+
+// AnsweringPlayer returns the id of the player due to answer a query, 0 if none.
+func (state GameState) AnsweringPlayer() PlayerID {
+	if state.state != GameStateQuery || state.answeringPlayer == -1 {
+		return 0
+	}
+
+	return state.players[state.answeringPlayer].id
+}
+
+// FailedSolution returns true if the player declared a wrong solution and is no more in play.
+func (state GameState) FailedSolution(id PlayerID) bool {
+	player, ok := state.Player(id)
+
+	return ok && state.failed(&player)
+}
+
+func (state *GameState) failed(player *PlayerState) bool {
+	return player.declaration != nil && *player.declaration != state.solution
+}
+
+// CanSuggest returns false if the house rules forbid the player to suggest in the room she/he is in
+// because she/he already did without leaving it.
+func (state GameState) CanSuggest(id PlayerID) bool {
+	player, ok := state.Player(id)
+
+	return ok && state.canSuggest(&player)
+}
+
+func (state *GameState) canSuggest(player *PlayerState) bool {
+	return !state.rules.NoRepeatedRoomSuggestion || player.suggestedIn != player.position.Room
+}
+
+// ReadyToStart returns true if there are enough players and all of them voted to start.
+func (state GameState) ReadyToStart() bool {
+	if state.state != GameStateStarting || len(state.players) < MinPlayers {
+		return false
+	}
+
+	for _, p := range state.players {
+		if !p.votedStart {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsValidPosition checks coordinate ranges.
+func (state GameState) IsValidPosition(mapX, mapY int) bool {
+	return state.variant.Board.IsValidPosition(mapX, mapY)
+}
+
+// IsOccupied returns the id of the player whose pawn is in the position, 0 if none.
+func (state GameState) IsOccupied(mapX, mapY int) PlayerID {
+	for _, p := range state.players {
+		if p.position.MapX == mapX && p.position.MapY == mapY {
+			return p.id
+		}
+	}
+
+	return 0
+}
+
+// IsSecretPassage checks if there is a secret passage.
+func (state GameState) IsSecretPassage(from, to Card) bool {
+	return state.variant.Board.IsSecretPassage(from, to)
+}
+
+// visited returns true if the current player has been in the position since
+// she/he rolled the dices.
+func (state *GameState) visited(position PawnPosition) bool {
+	for _, p := range state.path {
+		if p == position {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextTurnPlayer returns the next current player.
+func (state *GameState) nextTurnPlayer() (int, bool) {
+	c := state.currentPlayer
+	next := c
+
+	for {
+		next = (next + 1) % len(state.players)
+
+		if next == c {
+			return 0, true
+		}
+
+		if !state.failed(&state.players[next]) {
+			return next, false
+		}
+	}
+}
+
+// nextAnsweringPlayer returns the player due to try to reveal a card after from player,
+// following the answering order of the house rules.
+// It returns the current player when nobody is left to answer.
+func (state *GameState) nextAnsweringPlayer(from int) int {
+	step := 1
+
+	if state.rules.AnsweringOrder == Counterclockwise {
+		step = len(state.players) - 1
+	}
+
+	next := from
+
+	for {
+		next = (next + step) % len(state.players)
+
+		if next == state.currentPlayer || !state.rules.SkipEliminatedPlayers || !state.failed(&state.players[next]) {
+			return next
+		}
+	}
+}
+
+// Query returns the current query, EmptyDeclaration if none.
+func (state GameState) Query() Declaration {
+	if state.state != GameStateQuery && state.state != GameStateTrySolution {
+		return EmptyDeclaration
+	}
+
+	return state.query
+}
+
+// AnswerOptions returns the queried cards the answering player can show, nil if
+// nobody is answering or she/he has none of them.
+func (state GameState) AnswerOptions() []Card {
+	if state.AnsweringPlayer() == 0 {
+		return nil
+	}
+
+	answeringPlayer := &state.players[state.answeringPlayer]
+
+	var r []Card
+
+	for _, card := range []Card{state.query.Character, state.query.Weapon, state.query.Room} {
+		if answeringPlayer.HasCard(card) {
+			r = append(r, card)
+		}
+	}
+
+	return r
+}
+
+// PlayerTurnSequence returns the order in which players play each turn.
+func (state GameState) PlayerTurnSequence() []PlayerID {
+	var playersOrder []PlayerID
+
+	for _, player := range state.players {
+		playersOrder = append(playersOrder, player.id)
+	}
+
+	return playersOrder
+}
+
+// StartState returns the initial Cluedo game state.
+func (state GameState) StartState() StateUpdate {
+	var positions []PlayerPosition
+
+	for _, p := range state.players {
+		positions = append(positions, PlayerPosition{
+			PlayerID:     p.id,
+			PawnPosition: state.variant.Board.StartPosition(p.character),
+		})
+	}
+
+	return StateUpdate{
+		State:         GameStateNewTurn,
+		CurrentPlayer: state.players[0].id,
+		Positions:     positions,
+		Weapons:       state.startWeapons,
+		FaceUp:        state.faceUp,
+	}
+}
+
+// FullState return a fully compiled NotifyGameState so that web client can reinitialize from stratch.
+// Usually NotifyGameState contains only incremental changes.
+func (state GameState) FullState(askingPlayer PlayerID) StateUpdate {
+	r := StateUpdate{
+		State:         state.state,
+		CurrentPlayer: state.players[state.currentPlayer].id,
+	}
+
+	switch state.state {
+	case GameStateStarting:
+		// nop
+		break
+	case GameStateNewTurn:
+		r.Positions = state.PlayerPositions()
+		break
+	case GameStateCard:
+		// TODO
+		break
+	case GameStateMove:
+		r.Positions = state.PlayerPositions()
+		r.Dice1 = state.dice1
+		r.Dice2 = state.dice2
+		r.RemainingSteps = state.remainingSteps
+		r.Path = state.path
+		break
+	case GameStateQuery:
+		r.Positions = state.PlayerPositions()
+		r.Query = &state.query
+		if state.answeringPlayer >= 0 {
+			r.AnsweringPlayer = state.players[state.answeringPlayer].id
+		}
+		break
+	case GameStateTrySolution:
+		r.Positions = state.PlayerPositions()
+
+		r.Query = &state.query
+		r.Revealed = state.revealed
+		if askingPlayer == state.players[state.currentPlayer].id || state.rules.PublicReveals {
+			r.RevealedCard = state.revealedCard
+		}
+		break
+	case GameEnded:
+		currentPlayer := r.CurrentPlayer
+		r = state.endState()
+		r.CurrentPlayer = currentPlayer
+		break
+	}
+
+	if state.Started() {
+		r.FaceUp = state.faceUp
+		r.Weapons = state.WeaponPositions()
+	}
+
+	if state.Started() && state.state != GameEnded {
+		for _, player := range state.players {
+			if player.pulled {
+				r.PulledBySuggestion = append(r.PulledBySuggestion, player.id)
+			}
+		}
+	}
+
+	return r
+}
+
+// endState returns the final game state, revealing what was secret.
+func (state GameState) endState() StateUpdate {
+	return StateUpdate{
+		State:    GameEnded,
+		Solution: &state.solution,
+		Seed:     &state.seed,
+		Nonce:    state.nonce,
+		Decks:    state.Decks(),
+	}
+}
+
+// PlayerPositions return an array containing all players' position.
+func (state GameState) PlayerPositions() []PlayerPosition {
+	var r []PlayerPosition
+
+	for _, player := range state.players {
+		r = append(r, PlayerPosition{
+			PlayerID:     player.id,
+			PawnPosition: player.position,
+		})
+	}
+
+	return r
+}
+
+// ID returns the player id.
+func (player PlayerState) ID() PlayerID {
+	return player.id
+}
+
+// Character returns the character the player is.
+func (player PlayerState) Character() Card {
+	return player.character
+}
+
+// VotedStart returns true if the player voted to start the game.
+func (player PlayerState) VotedStart() bool {
+	return player.votedStart
+}
+
+// Deck returns the player deck. It must not be modified.
+func (player PlayerState) Deck() []Card {
+	return player.deck
+}
+
+// Position returns where the player pawn is.
+func (player PlayerState) Position() PawnPosition {
+	return player.position
+}
+
+// AutoAnswer returns true if the game answers for the player when she/he has at most one card to show.
+func (player PlayerState) AutoAnswer() bool {
+	return player.autoAnswer
+}
+
+// PulledBySuggestion returns true if a suggestion moved the pawn in a room since the player's
+// last turn: on her/his next turn she/he may suggest there without rolling the dices.
+func (player PlayerState) PulledBySuggestion() bool {
+	return player.pulled
+}
+
+// HasCard checks if the player has the card in her/his deck.
+func (player PlayerState) HasCard(card Card) bool {
+	for _, c := range player.deck {
+		if c == card {
+			return true
+		}
+	}
+
+	return false
+}
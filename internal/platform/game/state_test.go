@@ -0,0 +1,150 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(state *GameState)
+	}{
+		{"players", func(state *GameState) { state.players[0].position = inRoom(Hall) }},
+		{"deck", func(state *GameState) { state.players[0].deck[0] = Rope }},
+		{"declaration", func(state *GameState) { state.players[1].declaration.Weapon = Rope }},
+		{"face up cards", func(state *GameState) { state.faceUp[0] = Rope }},
+		{"weapons", func(state *GameState) { state.weapons[0].Room = Hall }},
+		{"start weapons", func(state *GameState) { state.startWeapons[0].Room = Hall }},
+		{"path", func(state *GameState) { state.path[0] = PositionAt(1, 1) }},
+		{"random", func(state *GameState) { state.random.Uint64() }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := with(moving(PositionAt(7, 24), 3), func(state *GameState) {
+				state.players[1].declaration = &Declaration{Character: ProfPlum, Room: Hall, Weapon: Knife}
+				state.faceUp = []Card{Knife}
+			})
+
+			before := with(state, func(state *GameState) {
+				// a deep copy built by hand, not to rely on what is tested
+				state.players = append([]PlayerState(nil), state.players...)
+				state.players[0].deck = append([]Card(nil), state.players[0].deck...)
+				declaration := *state.players[1].declaration
+				state.players[1].declaration = &declaration
+				state.faceUp = append([]Card(nil), state.faceUp...)
+				state.weapons = append([]WeaponPosition(nil), state.weapons...)
+				state.startWeapons = append([]WeaponPosition(nil), state.startWeapons...)
+				state.path = append([]PawnPosition(nil), state.path...)
+			})
+
+			clone := state.Clone()
+
+			test.modify(&clone)
+
+			if !reflect.DeepEqual(state, before) {
+				t.Error("modifying the clone modified the state")
+			}
+
+			if reflect.DeepEqual(clone, before) {
+				t.Error("the clone has not been modified")
+			}
+		})
+	}
+}
+
+func TestDraw(t *testing.T) {
+	start := func(seed Seed) GameState {
+		state := NewState(Classic, Rules{}, seed)
+
+		for _, character := range []Card{MrsPeacock, ColMustard} {
+			var id PlayerID
+
+			state, id, _ = state.AddPlayer()
+			state, _, _ = Apply(state, id, SelectCharacterAction{Character: character})
+		}
+
+		state, _, err := Apply(state, 0, StartAction{Nonce: "nonce"})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return state
+	}
+
+	roll := func(state GameState) (GameState, [2]int) {
+		next, records, err := Apply(state, state.CurrentPlayer(), RollDicesAction{})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		move := records[0].Move.(*RollDicesMove)
+
+		return next, [2]int{move.Dice1, move.Dice2}
+	}
+
+	// rolls returns the dices of n turns spent rolling and then stopping at once
+	rolls := func(state GameState, n int) [][2]int {
+		var r [][2]int
+
+		for i := 0; i < n; i++ {
+			var dices [2]int
+
+			state, dices = roll(state)
+			r = append(r, dices)
+
+			for _, action := range []Action{StopMovingAction{}, PassAction{}} {
+				var err error
+
+				if state, _, err = Apply(state, state.CurrentPlayer(), action); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		return r
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{"same seed same deal", func(t *testing.T) {
+			a, b := start(SeedFromID(7)), start(SeedFromID(7))
+
+			if !reflect.DeepEqual(a.Decks(), b.Decks()) || a.solution != b.solution || !reflect.DeepEqual(a.weapons, b.weapons) {
+				t.Error("same seed, different deal")
+			}
+		}},
+		{"same seed same dices", func(t *testing.T) {
+			if a, b := rolls(start(SeedFromID(7)), 20), rolls(start(SeedFromID(7)), 20); !reflect.DeepEqual(a, b) {
+				t.Errorf("same seed, different dices: %v and %v", a, b)
+			}
+		}},
+		{"stale state", func(t *testing.T) {
+			state := start(SeedFromID(7))
+
+			first := rolls(state, 20)
+
+			// drawing from the following states doesn't affect the values drawn from state
+			_, dices := roll(state)
+			_ = rolls(state, 5)
+
+			if again := rolls(state, 20); !reflect.DeepEqual(first, again) || dices != first[0] {
+				t.Errorf("a stale state draws %v, then %v", first, again)
+			}
+		}},
+		{"different seeds", func(t *testing.T) {
+			if a, b := rolls(start(SeedFromID(7)), 20), rolls(start(SeedFromID(8)), 20); reflect.DeepEqual(a, b) {
+				t.Error("different seeds, same dices")
+			}
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, test.run)
+	}
+}
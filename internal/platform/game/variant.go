@@ -0,0 +1,109 @@
+package game
+
+const (
+	// ClassicVariant is the name of the classic variant: 6 characters, 6 weapons and 9 rooms.
+	ClassicVariant = "classic"
+
+	// MinPlayers is the number of players needed to start a game, whatever the variant.
+	MinPlayers = 2
+)
+
+// masterDetectiveVariant is the name of the Master Detective variant:
+// 10 characters, 8 weapons and 12 rooms.
+const masterDetectiveVariant = "master_detective"
+
+// Variant is a flavour of the game: its card catalogue, its board and how many
+// players can sit at the table.
+type Variant struct {
+	Name       string
+	Characters []Card
+	Weapons    []Card
+	Rooms      []Card
+	MaxPlayers int
+	Board      *Board
+}
+
+// Classic is the classic variant.
+var Classic = &Variant{
+	Name:       ClassicVariant,
+	Characters: []Card{MissScarlett, RevGreen, ColMustard, ProfPlum, MrsPeacock, MrsWhite},
+	Weapons:    []Card{Candlestick, Knife, LeadPipe, Revolver, Rope, Wrenck},
+	Rooms:      []Card{Kitchen, Ballroom, Conservatory, DiningRoom, BilliardRoom, Library, Lounge, Hall, Study},
+	MaxPlayers: 6,
+	Board:      classicBoard,
+}
+
+// masterDetective is the Master Detective variant. It is not among Variants, so no game
+// can be created with it, until its placeholder board is replaced by the real one, with
+// its 12 rooms, doors and secret passages.
+var masterDetective = &Variant{
+	Name: masterDetectiveVariant,
+	Characters: []Card{
+		MissScarlett, RevGreen, ColMustard, ProfPlum, MrsPeacock, MrsWhite,
+		MadameRose, SergeantGray, MonsieurBrunette, MissPeach,
+	},
+	Weapons: []Card{Candlestick, Knife, LeadPipe, Revolver, Rope, Wrenck, Poison, Horseshoe},
+	Rooms: []Card{
+		CarriageHouse, Kitchen, TrophyRoom, DiningRoom,
+		Conservatory, Courtyard, Fountain, DrawingRoom,
+		Gazebo, Library, BilliardRoom, Studio,
+	},
+	MaxPlayers: 10,
+	Board:      masterDetectiveBoard,
+}
+
+// Variants returns all the supported variants.
+func Variants() []*Variant {
+	return []*Variant{Classic}
+}
+
+// VariantByName returns the variant with the given name, the classic one if name is empty.
+func VariantByName(name string) (*Variant, bool) {
+	if name == "" {
+		return Classic, true
+	}
+
+	for _, v := range Variants() {
+		if v.Name == name {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// IsCharacter returns true if the card is a character of the variant.
+func (variant *Variant) IsCharacter(card Card) bool {
+	return contains(variant.Characters, card)
+}
+
+// IsWeapon returns true if the card is a weapon of the variant.
+func (variant *Variant) IsWeapon(card Card) bool {
+	return contains(variant.Weapons, card)
+}
+
+// IsRoom returns true if the card is a room of the variant.
+func (variant *Variant) IsRoom(card Card) bool {
+	return contains(variant.Rooms, card)
+}
+
+// Cards returns all the cards of the variant: weapons, rooms and characters.
+func (variant *Variant) Cards() []Card {
+	r := make([]Card, 0, len(variant.Weapons)+len(variant.Rooms)+len(variant.Characters))
+
+	r = append(r, variant.Weapons...)
+	r = append(r, variant.Rooms...)
+	r = append(r, variant.Characters...)
+
+	return r
+}
+
+func contains(cards []Card, card Card) bool {
+	for _, c := range cards {
+		if c == card {
+			return true
+		}
+	}
+
+	return false
+}
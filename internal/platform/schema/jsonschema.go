@@ -0,0 +1,123 @@
+package schema
+
+// JSONSchema returns the protocol as a JSON Schema (draft-07) document.
+// Payloads are in definitions, while x-requests and x-messages map each message
+// type to its payload ({"type": "null"} if the message has none).
+// x-variants lists the cards of each game variant.
+func JSONSchema(p *Protocol) map[string]interface{} {
+	definitions := make(map[string]interface{})
+
+	for _, t := range p.Types {
+		definitions[t.Name] = jsonTypeDef(t)
+	}
+
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "My Clue BE protocol",
+		"definitions": definitions,
+		"x-protocols": p.Protocols,
+		"x-requests":  jsonMessages(p.Requests),
+		"x-messages":  jsonMessages(p.Messages),
+		"x-variants":  jsonVariants(p.Variants),
+	}
+}
+
+func jsonVariants(variants []Variant) map[string]interface{} {
+	r := make(map[string]interface{})
+
+	for _, v := range variants {
+		r[v.Name] = map[string]interface{}{
+			"characters":  v.Characters,
+			"weapons":     v.Weapons,
+			"rooms":       v.Rooms,
+			"max_players": v.MaxPlayers,
+		}
+	}
+
+	return r
+}
+
+func jsonMessages(messages []Message) map[string]interface{} {
+	r := make(map[string]interface{})
+
+	for _, message := range messages {
+		if message.Body == nil {
+			r[string(message.Type)] = map[string]interface{}{"type": "null"}
+		} else {
+			r[string(message.Type)] = jsonTypeRef(message.Body)
+		}
+	}
+
+	return r
+}
+
+func jsonTypeDef(t *TypeDef) map[string]interface{} {
+	switch {
+	case t.Enum != nil:
+		values := make([]int, len(t.Enum))
+		names := make([]string, len(t.Enum))
+
+		for i, v := range t.Enum {
+			values[i] = v.Value
+			names[i] = v.Name
+		}
+
+		return map[string]interface{}{
+			"type":            "integer",
+			"enum":            values,
+			"x-enum-varnames": names,
+		}
+
+	case t.Union != nil:
+		var oneOf []interface{}
+
+		for _, name := range t.Union {
+			oneOf = append(oneOf, jsonTypeRef(&TypeRef{Kind: Named, Name: name}))
+		}
+
+		return map[string]interface{}{
+			"oneOf": oneOf,
+		}
+
+	default:
+		properties := make(map[string]interface{})
+		required := []string{}
+
+		for _, f := range t.Fields {
+			properties[f.Name] = jsonTypeRef(f.Type)
+
+			if !f.Optional {
+				required = append(required, f.Name)
+			}
+		}
+
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	}
+}
+
+func jsonTypeRef(ref *TypeRef) map[string]interface{} {
+	switch ref.Kind {
+	case Named:
+		return map[string]interface{}{"$ref": "#/definitions/" + ref.Name}
+	case Integer:
+		return map[string]interface{}{"type": "integer"}
+	case Number:
+		return map[string]interface{}{"type": "number"}
+	case String:
+		return map[string]interface{}{"type": "string"}
+	case Boolean:
+		return map[string]interface{}{"type": "boolean"}
+	case DateTime:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case Array:
+		return map[string]interface{}{"type": "array", "items": jsonTypeRef(ref.Elem)}
+	case Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonTypeRef(ref.Elem)}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,354 @@
+// Package schema describes My Clue BE websocket protocol by reflecting over
+// request and message payloads, so that clients can generate their types
+// instead of copying them by hand.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+)
+
+// RefKind is the kind of a TypeRef.
+type RefKind int
+
+const (
+	// Named refers to a TypeDef.
+	Named RefKind = iota
+	// Integer is a json integer.
+	Integer
+	// Number is a json number.
+	Number
+	// String is a json string.
+	String
+	// Boolean is a json boolean.
+	Boolean
+	// DateTime is a json string formatted as RFC 3339.
+	DateTime
+	// Array is a json array of Elem.
+	Array
+	// Map is a json object whose values are Elem.
+	Map
+	// Any is any json value.
+	Any
+)
+
+// TypeRef is a reference to a type used by a field or a message.
+type TypeRef struct {
+	Kind RefKind
+	// Name is defined if Kind is Named.
+	Name string
+	// Elem is defined if Kind is Array or Map.
+	Elem *TypeRef
+}
+
+// Field is a property of a struct TypeDef.
+type Field struct {
+	Name     string
+	Type     *TypeRef
+	Optional bool
+}
+
+// EnumValue is a value of an enum TypeDef.
+type EnumValue struct {
+	Name  string
+	Value int
+}
+
+// TypeDef is a named type: a struct if Fields is defined, an integer enum
+// if Enum is defined, or a union of other TypeDefs if Union is defined.
+type TypeDef struct {
+	Name   string
+	Fields []Field
+	Enum   []EnumValue
+	Union  []string
+}
+
+// Message describes a message type and its payload, Body is nil if the
+// message has none.
+type Message struct {
+	Type data.MessageType
+	Body *TypeRef
+}
+
+// Protocol describes all the messages exchanged by clients and server.
+type Protocol struct {
+	Protocols []string
+	// Requests are sent by clients.
+	Requests []Message
+	// Messages are sent by the server: responses and notifications.
+	Messages []Message
+	// Types are sorted by name.
+	Types []*TypeDef
+	// Variants are the card catalogues of the game variants.
+	Variants []Variant
+}
+
+// Variant lists the names of the cards of a game variant.
+type Variant struct {
+	Name       string
+	Characters []string
+	Weapons    []string
+	Rooms      []string
+	MaxPlayers int
+}
+
+var (
+	moveType     = reflect.TypeOf((*game.Move)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	rawType      = reflect.TypeOf(json.RawMessage{})
+	recordType   = reflect.TypeOf(game.MoveRecord{})
+	cardType     = reflect.TypeOf(game.NoCard)
+	stateType    = reflect.TypeOf(game.GameStateStarting)
+	moveTypeType = reflect.TypeOf(game.Start)
+	seedType     = reflect.TypeOf(game.Seed{})
+)
+
+type builder struct {
+	types map[string]*TypeDef
+}
+
+// Build describes the protocol spoken by a server with the given handlers.
+func Build(handlers []web.RequestHandler) *Protocol {
+	b := &builder{
+		types: make(map[string]*TypeDef),
+	}
+
+	p := &Protocol{
+		Protocols: data.Protocols,
+	}
+
+	for _, handler := range handlers {
+		p.Requests = append(p.Requests, Message{
+			Type: handler.RequestType(),
+			Body: b.bodyRef(handler.NewBody()),
+		})
+	}
+
+	for messageType, body := range data.ServerMessages {
+		p.Messages = append(p.Messages, Message{
+			Type: messageType,
+			Body: b.bodyRef(body),
+		})
+	}
+
+	// the header is not a payload but clients need it too
+	b.ref(reflect.TypeOf(data.Envelope{}))
+
+	sortMessages(p.Requests)
+	sortMessages(p.Messages)
+
+	for _, t := range b.types {
+		p.Types = append(p.Types, t)
+	}
+
+	sort.Slice(p.Types, func(i, j int) bool {
+		return p.Types[i].Name < p.Types[j].Name
+	})
+
+	for _, v := range game.Variants() {
+		p.Variants = append(p.Variants, Variant{
+			Name:       v.Name,
+			Characters: cardNames(v.Characters),
+			Weapons:    cardNames(v.Weapons),
+			Rooms:      cardNames(v.Rooms),
+			MaxPlayers: v.MaxPlayers,
+		})
+	}
+
+	return p
+}
+
+func cardNames(cards []game.Card) []string {
+	r := make([]string, len(cards))
+
+	for i, card := range cards {
+		r[i] = card.String()
+	}
+
+	return r
+}
+
+func sortMessages(messages []Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Type < messages[j].Type
+	})
+}
+
+func (b *builder) bodyRef(body interface{}) *TypeRef {
+	if body == nil {
+		return nil
+	}
+
+	return b.ref(reflect.TypeOf(body))
+}
+
+func (b *builder) ref(t reflect.Type) *TypeRef {
+	switch t {
+	case timeType:
+		return &TypeRef{Kind: DateTime}
+	case rawType:
+		return &TypeRef{Kind: Any}
+	case seedType:
+		// see Seed.MarshalText
+		return &TypeRef{Kind: String}
+	case cardType:
+		return b.enum(t, enumValues(len(game.AllCards()), func(i int) string { return game.Card(i).String() }, 0))
+	case stateType:
+		return b.enum(t, enumValues(len(game.AllStates()), func(i int) string { return game.State(i).String() }, 0))
+	case moveTypeType:
+		return b.enum(t, enumValues(len(game.AllMoveTypes()), func(i int) string { return game.MoveType(i).String() }, 1))
+	case moveType:
+		return b.moveUnion(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.ref(t.Elem())
+	case reflect.Bool:
+		return &TypeRef{Kind: Boolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &TypeRef{Kind: Integer}
+	case reflect.Float32, reflect.Float64:
+		return &TypeRef{Kind: Number}
+	case reflect.String:
+		return &TypeRef{Kind: String}
+	case reflect.Slice, reflect.Array:
+		return &TypeRef{Kind: Array, Elem: b.ref(t.Elem())}
+	case reflect.Map:
+		return &TypeRef{Kind: Map, Elem: b.ref(t.Elem())}
+	case reflect.Struct:
+		return b.structRef(t)
+	default:
+		return &TypeRef{Kind: Any}
+	}
+}
+
+func enumValues(count int, name func(int) string, first int) []EnumValue {
+	values := make([]EnumValue, count)
+
+	for i := range values {
+		values[i] = EnumValue{
+			Name:  name(i + first),
+			Value: i + first,
+		}
+	}
+
+	return values
+}
+
+func (b *builder) enum(t reflect.Type, values []EnumValue) *TypeRef {
+	if _, ok := b.types[t.Name()]; !ok {
+		b.types[t.Name()] = &TypeDef{
+			Name: t.Name(),
+			Enum: values,
+		}
+	}
+
+	return &TypeRef{Kind: Named, Name: t.Name()}
+}
+
+func (b *builder) moveUnion(t reflect.Type) *TypeRef {
+	if _, ok := b.types[t.Name()]; ok {
+		return &TypeRef{Kind: Named, Name: t.Name()}
+	}
+
+	def := &TypeDef{
+		Name: t.Name(),
+	}
+
+	b.types[t.Name()] = def
+
+	for _, mt := range game.AllMoveTypes() {
+		move := game.NewMove(mt)
+
+		def.Union = append(def.Union, b.ref(reflect.TypeOf(move)).Name)
+	}
+
+	return &TypeRef{Kind: Named, Name: t.Name()}
+}
+
+func (b *builder) structRef(t reflect.Type) *TypeRef {
+	if _, ok := b.types[t.Name()]; ok {
+		return &TypeRef{Kind: Named, Name: t.Name()}
+	}
+
+	def := &TypeDef{
+		Name:   t.Name(),
+		Fields: []Field{},
+	}
+
+	// registered before visiting fields to stop recursion
+	b.types[t.Name()] = def
+
+	def.Fields = b.fields(t)
+
+	if t == recordType {
+		// see MoveRecord.MarshalJSON
+		def.Fields = append(def.Fields, Field{
+			Name: "type",
+			Type: b.ref(moveTypeType),
+		})
+	}
+
+	return &TypeRef{Kind: Named, Name: t.Name()}
+}
+
+// fields returns the json properties of a struct, embedded structs are flattened
+// the same way encoding/json does.
+func (b *builder) fields(t reflect.Type) []Field {
+	var fields []Field
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+
+		if p := strings.Index(tag, ","); p >= 0 {
+			name, options = tag[:p], tag[p+1:]
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, b.fields(ft)...)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, Field{
+			Name:     name,
+			Type:     b.ref(f.Type),
+			Optional: strings.Contains(options, "omitempty") || f.Type.Kind() == reflect.Ptr,
+		})
+	}
+
+	return fields
+}
@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteTypeScript writes the protocol as TypeScript definitions.
+// Enums keep their Go values and are named after the snake case names of the
+// game package, eg. game.LeadPipe (lead_pipe) becomes Card.LeadPipe.
+func WriteTypeScript(w io.Writer, p *Protocol) error {
+	ts := &tsWriter{w: w}
+
+	ts.printf("// Code generated by clue-schema. DO NOT EDIT.\n\n")
+
+	ts.printf("export const PROTOCOLS = [%s];\n", quoteAll(p.Protocols))
+
+	for _, t := range p.Types {
+		ts.printf("\n")
+
+		switch {
+		case t.Enum != nil:
+			ts.printf("export enum %s {\n", t.Name)
+
+			for _, v := range t.Enum {
+				ts.printf("  %s = %d,\n", pascalCase(v.Name), v.Value)
+			}
+
+			ts.printf("}\n")
+
+		case t.Union != nil:
+			ts.printf("export type %s = %s;\n", t.Name, strings.Join(t.Union, " | "))
+
+		default:
+			ts.printf("export interface %s {\n", t.Name)
+
+			for _, f := range t.Fields {
+				optional := ""
+
+				if f.Optional {
+					optional = "?"
+				}
+
+				ts.printf("  %s%s: %s;\n", f.Name, optional, tsTypeRef(f.Type))
+			}
+
+			ts.printf("}\n")
+		}
+	}
+
+	ts.messages("Requests", p.Requests)
+	ts.messages("ServerMessages", p.Messages)
+
+	ts.printf("\nexport type RequestType = keyof Requests;\n")
+	ts.printf("export type ServerMessageType = keyof ServerMessages;\n")
+
+	ts.variants(p.Variants)
+
+	return ts.err
+}
+
+// variants writes the card catalogue of each game variant.
+func (ts *tsWriter) variants(variants []Variant) {
+	ts.printf("\nexport const VARIANTS = {\n")
+
+	for _, v := range variants {
+		ts.printf("  %s: {\n", v.Name)
+		ts.printf("    maxPlayers: %d,\n", v.MaxPlayers)
+		ts.printf("    characters: [%s],\n", cardMembers(v.Characters))
+		ts.printf("    weapons: [%s],\n", cardMembers(v.Weapons))
+		ts.printf("    rooms: [%s],\n", cardMembers(v.Rooms))
+		ts.printf("  },\n")
+	}
+
+	ts.printf("};\n")
+}
+
+func cardMembers(names []string) string {
+	members := make([]string, len(names))
+
+	for i, name := range names {
+		members[i] = "Card." + pascalCase(name)
+	}
+
+	return strings.Join(members, ", ")
+}
+
+type tsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ts *tsWriter) printf(format string, args ...interface{}) {
+	if ts.err != nil {
+		return
+	}
+
+	_, ts.err = fmt.Fprintf(ts.w, format, args...)
+}
+
+func (ts *tsWriter) messages(name string, messages []Message) {
+	ts.printf("\nexport interface %s {\n", name)
+
+	for _, message := range messages {
+		body := "null"
+
+		if message.Body != nil {
+			body = tsTypeRef(message.Body)
+		}
+
+		ts.printf("  %s: %s;\n", message.Type, body)
+	}
+
+	ts.printf("}\n")
+}
+
+func tsTypeRef(ref *TypeRef) string {
+	switch ref.Kind {
+	case Named:
+		return ref.Name
+	case Integer, Number:
+		return "number"
+	case String, DateTime:
+		return "string"
+	case Boolean:
+		return "boolean"
+	case Array:
+		return tsTypeRef(ref.Elem) + "[]"
+	case Map:
+		return "{ [key: string]: " + tsTypeRef(ref.Elem) + " }"
+	default:
+		return "unknown"
+	}
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// pascalCase converts a snake case name, eg. lead_pipe, to LeadPipe.
+func pascalCase(name string) string {
+	parts := strings.Split(name, "_")
+
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return strings.Join(parts, "")
+}
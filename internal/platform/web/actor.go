@@ -0,0 +1,224 @@
+package web
+
+import (
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/events"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// mailboxSize is the number of functions queued to a game goroutine before blocking the senders.
+const mailboxSize = 64
+
+// newServerGame builds a table and starts its goroutine.
+func newServerGame(g *game.Game, registry *registry, bus *events.Bus, journalSize int, idleTimeout time.Duration) *serverGame {
+	sg := &serverGame{
+		game:        g,
+		registry:    registry,
+		bus:         bus,
+		mailbox:     make(chan func(), mailboxSize),
+		journal:     newJournal(journalSize),
+		idleTimeout: idleTimeout,
+	}
+
+	go sg.run()
+
+	return sg
+}
+
+// run executes the functions posted to the game, one at a time and in order, until
+// the mailbox is closed, see close.
+func (g *serverGame) run() {
+	for f := range g.mailbox {
+		g.exec(f)
+
+		if g.closing && !g.closed {
+			g.unregister()
+			go g.closeMailbox()
+		}
+	}
+}
+
+// exec calls a function posted to the game, recovering from its panics: a failing
+// function is logged and the game goes on with the following ones.
+func (g *serverGame) exec(f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("game function failed: game=", g.game.ID(), "error=", r, "\n", string(debug.Stack()))
+		}
+	}()
+
+	f()
+}
+
+// post queues a function to be executed by the game goroutine.
+// It returns false if the mailbox has been closed: the function is not executed.
+func (g *serverGame) post(f func()) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.mailboxClosed {
+		return false
+	}
+
+	g.mailbox <- f
+
+	return true
+}
+
+// call executes a function in the game goroutine and waits for it to complete.
+// It returns false if the mailbox has been closed: the function is not executed.
+// It must not be called by a game goroutine nor holding registry.mu.
+func (g *serverGame) call(f func()) bool {
+	done := make(chan struct{})
+
+	ok := g.post(func() {
+		defer close(done)
+
+		f()
+	})
+
+	if ok {
+		<-done
+	}
+
+	return ok
+}
+
+// close stops serving the game once the current function returns: the game is dropped
+// from the registry, so that no more requests are dispatched to it, and the goroutine
+// exits after executing the functions already posted. It must be called by the game goroutine.
+func (g *serverGame) close() {
+	g.closing = true
+}
+
+// unregister drops the game from the registry, and from the games joined and followed by its players.
+func (g *serverGame) unregister() {
+	g.closed = true
+
+	r := g.registry
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.games, g.game.ID())
+
+	for _, gu := range g.players {
+		for i, joined := range gu.user.joinedGames {
+			if joined == gu {
+				gu.user.joinedGames = append(gu.user.joinedGames[:i:i], gu.user.joinedGames[i+1:]...)
+				break
+			}
+		}
+
+		if gu.io != nil && gu.io.games[g.game.ID()] == gu {
+			delete(gu.io.games, g.game.ID())
+		}
+	}
+}
+
+// closeMailbox closes the mailbox once no post is in progress. It is not executed by the game
+// goroutine: the posts in progress may be waiting for it to make room in the mailbox.
+func (g *serverGame) closeMailbox() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.mailboxClosed = true
+
+	close(g.mailbox)
+}
+
+// online returns true if a player is following the game.
+func (g *serverGame) online() bool {
+	for _, gu := range g.players {
+		if gu.io != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// closeIfIdle closes the game if nobody follows it for idleTimeout, if set.
+// It must be called by the game goroutine when the last player leaves.
+func (g *serverGame) closeIfIdle() {
+	if g.idleTimeout <= 0 {
+		return
+	}
+
+	g.idleChecks++
+
+	check := g.idleChecks
+
+	time.AfterFunc(g.idleTimeout, func() {
+		g.post(func() {
+			// a player came back, maybe left again scheduling a new check
+			if check == g.idleChecks && !g.online() {
+				g.close()
+			}
+		})
+	})
+}
+
+// player returns the player following the game through the given ws, nil if none.
+func (g *serverGame) player(userIO *UserIO) *gameUser {
+	for _, gu := range g.players {
+		if gu.io == userIO {
+			return gu
+		}
+	}
+
+	return nil
+}
+
+// leave marks the player offline, if she/he was still following the game through the closed ws,
+// and notifies the others.
+func (g *serverGame) leave(gu *gameUser, userIO *UserIO) {
+	if gu.io != userIO {
+		return
+	}
+
+	gu.io = nil
+
+	userState := gu.State()
+
+	g.notifyPlayers(gu.player, data.MessageNotifyUserState, func(target *game.Player) interface{} {
+		return userState
+	})
+
+	g.bus.Publish(&events.PlayerLeft{
+		Header:   events.NewHeader(g.game.ID()),
+		PlayerID: gu.player.ID(),
+		User:     userState.Name,
+	})
+
+	if !g.online() {
+		g.closeIfIdle()
+	}
+}
+
+// resync queues the full state of the game to a ws whose notifications have been dropped,
+// see OverflowResync.
+func (g *serverGame) resync(userIO *UserIO) {
+	gu := g.player(userIO)
+
+	if gu == nil {
+		userIO.outbox.resynced(g.game.ID(), nil)
+
+		return
+	}
+
+	userIO.outbox.resynced(g.game.ID(), &data.MessageFrame{
+		Header: data.MessageHeader{
+			Type:   data.MessageNotifyFullState,
+			GameID: g.game.ID(),
+			Seq:    g.seq,
+		},
+		Body: g.fullState(gu),
+	})
+
+	g.resendAnswerOptions(gu)
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+// start starts the goroutine of a game built by newTestGame.
+func start(sg *serverGame) {
+	sg.mailbox = make(chan func(), mailboxSize)
+
+	go sg.run()
+}
+
+func TestExecRecovers(t *testing.T) {
+	var logged bytes.Buffer
+
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	_, sg := newTestGame(0, "alice")
+
+	start(sg)
+
+	sg.post(func() {
+		panic("boom")
+	})
+
+	served := false
+
+	if !sg.call(func() { served = true }) || !served {
+		t.Fatal("the game stopped serving after a panic")
+	}
+
+	if !bytes.Contains(logged.Bytes(), []byte("boom")) {
+		t.Errorf("panic not logged: %s", logged.String())
+	}
+}
+
+func TestClose(t *testing.T) {
+	server, sg := newTestGame(0, "alice", "bob")
+
+	start(sg)
+
+	sg.call(sg.close)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for sg.post(func() {}) {
+		if time.Now().After(deadline) {
+			t.Fatal("the mailbox has not been closed")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if server.registry.game(sg.game.ID()) != nil {
+		t.Error("the closed game is still registered")
+	}
+
+	for _, gu := range sg.players {
+		if len(gu.user.joinedGames) != 0 || len(gu.io.games) != 0 {
+			t.Errorf("%s still plays the closed game", gu.user.name)
+		}
+	}
+}
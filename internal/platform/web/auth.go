@@ -8,6 +8,11 @@ import (
 
 // SignIn registers a new user.
 func (server *Server) SignIn(userIO *UserIO, name string) (*User, string) {
+	r := &server.registry
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	user := userIO.user
 
 	if user != nil {
@@ -19,16 +24,16 @@ func (server *Server) SignIn(userIO *UserIO, name string) (*User, string) {
 	}
 
 	user = &User{
-		token: server.randomUserToken(),
+		token: r.randomUserToken(),
 		name:  name,
 	}
 
 	userIO.user = user
 	user.io = append(user.io, userIO)
 
-	server.signedUsers[user.token] = user
+	r.signedUsers[user.token] = user
 
-	server.removeConnectedUser(userIO)
+	r.removeConnectedUser(userIO)
 
 	log.Println("new user: name=", user.name)
 
@@ -37,6 +42,11 @@ func (server *Server) SignIn(userIO *UserIO, name string) (*User, string) {
 
 // Authenticate checks provided token against known users.
 func (server *Server) Authenticate(userIO *UserIO, name string, token string) (*User, error) {
+	r := &server.registry
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	user := userIO.user
 
 	if user != nil {
@@ -61,7 +71,7 @@ func (server *Server) Authenticate(userIO *UserIO, name string, token string) (*
 	} else {
 		// signin request from a disconnected user?
 
-		user = server.signedUsers[token]
+		user = r.signedUsers[token]
 
 		if user == nil {
 			return nil, game.UnknownToken
@@ -74,7 +84,7 @@ func (server *Server) Authenticate(userIO *UserIO, name string, token string) (*
 		// log.Println("user back online: token=", token)
 	}
 
-	server.removeConnectedUser(userIO)
+	r.removeConnectedUser(userIO)
 
 	// log.Println("io before adding", user.io)
 
@@ -83,3 +93,13 @@ func (server *Server) Authenticate(userIO *UserIO, name string, token string) (*
 
 	return user, nil
 }
+
+// SetLanguage chooses the language of the error messages sent to the user, eg. it or en-US.
+// Unsupported languages are ignored.
+func (server *Server) SetLanguage(user *User, tag string) {
+	if language, ok := supportedLanguage(tag); ok {
+		server.registry.mu.Lock()
+		user.language = language
+		server.registry.mu.Unlock()
+	}
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// defaultLanguage is the language of the error messages of the users that didn't choose
+// a supported one.
+const defaultLanguage = "en"
+
+// errorMessages are the human readable error messages, indexed by language and code.
+var errorMessages = map[string]map[game.Error]string{
+	"en": {
+		game.UnknownRequest:         "Unknown request.",
+		game.BadRequest:             "The request can't be decoded.",
+		game.NotSignedIn:            "Sign in first.",
+		game.CannotJoinRunningGame:  "The game has already started.",
+		game.TableIsFull:            "The table is full.",
+		game.TokenMismatch:          "You are signed in as another user.",
+		game.UnknownToken:           "Unknown user, sign in again.",
+		game.TooManyGames:           "You are playing too many games.",
+		game.UnknownGame:            "Unknown game.",
+		game.AlreadyPlaying:         "You are already playing this game in another window.",
+		game.AlreadySelected:        "The character has already been selected.",
+		game.NotACharacter:          "That card is not a character.",
+		game.NotAWeapon:             "That card is not a weapon.",
+		game.NotARoom:               "That card is not a room.",
+		game.UnknownVariant:         "Unknown game variant.",
+		game.BadRules:               "Invalid house rules.",
+		game.NotPlaying:             "You are not playing this game.",
+		game.GameAlreadyStarted:     "The game has already started.",
+		game.CharacterNotSelected:   "Select a character first.",
+		game.NotEnoughPlayers:       "Not enough players to start the game.",
+		game.NotYourTurn:            "It's not your turn.",
+		game.IllegalState:           "You can't do that now.",
+		game.IllegalMove:            "You can't move there.",
+		game.AlreadyVisited:         "You have already been there in this turn.",
+		game.DoorBlocked:            "Another pawn is blocking the door.",
+		game.NotYourCard:            "You don't have that card.",
+		game.CardNotQueried:         "That card has not been asked.",
+		game.MustShowACard:          "You have a card to show.",
+		game.NotInARoom:             "You are not in a room.",
+		game.NotPulledBySuggestion:  "Roll the dices: no suggestion moved you here.",
+		game.RepeatedRoomSuggestion: "Leave the room before suggesting here again.",
+		game.TooManyRequests:        "Too many requests, slow down.",
+		game.InternalError:          "Something went wrong, please retry.",
+	},
+	"it": {
+		game.UnknownRequest:         "Richiesta sconosciuta.",
+		game.BadRequest:             "La richiesta non può essere decodificata.",
+		game.NotSignedIn:            "Devi prima accedere.",
+		game.CannotJoinRunningGame:  "La partita è già iniziata.",
+		game.TableIsFull:            "Il tavolo è al completo.",
+		game.TokenMismatch:          "Hai effettuato l'accesso come un altro utente.",
+		game.UnknownToken:           "Utente sconosciuto, accedi di nuovo.",
+		game.TooManyGames:           "Stai giocando troppe partite.",
+		game.UnknownGame:            "Partita sconosciuta.",
+		game.AlreadyPlaying:         "Stai già giocando questa partita in un'altra finestra.",
+		game.AlreadySelected:        "Il personaggio è già stato scelto.",
+		game.NotACharacter:          "Quella carta non è un personaggio.",
+		game.NotAWeapon:             "Quella carta non è un'arma.",
+		game.NotARoom:               "Quella carta non è una stanza.",
+		game.UnknownVariant:         "Variante di gioco sconosciuta.",
+		game.BadRules:               "Regole della casa non valide.",
+		game.NotPlaying:             "Non stai giocando questa partita.",
+		game.GameAlreadyStarted:     "La partita è già iniziata.",
+		game.CharacterNotSelected:   "Scegli prima un personaggio.",
+		game.NotEnoughPlayers:       "Non ci sono abbastanza giocatori per iniziare la partita.",
+		game.NotYourTurn:            "Non è il tuo turno.",
+		game.IllegalState:           "Non puoi farlo adesso.",
+		game.IllegalMove:            "Non puoi muoverti lì.",
+		game.AlreadyVisited:         "Ci sei già passato in questo turno.",
+		game.DoorBlocked:            "Un'altra pedina blocca la porta.",
+		game.NotYourCard:            "Non hai quella carta.",
+		game.CardNotQueried:         "Quella carta non è stata chiesta.",
+		game.MustShowACard:          "Hai una carta da mostrare.",
+		game.NotInARoom:             "Non sei in una stanza.",
+		game.NotPulledBySuggestion:  "Tira i dadi: non ti ha spostato qui un'ipotesi.",
+		game.RepeatedRoomSuggestion: "Esci dalla stanza prima di fare di nuovo un'ipotesi qui.",
+		game.TooManyRequests:        "Troppe richieste, rallenta.",
+		game.InternalError:          "Qualcosa è andato storto, riprova.",
+	},
+}
+
+// supportedLanguage returns the language of the error messages best matching a language tag,
+// eg. it for it-IT, and false if there is none.
+func supportedLanguage(tag string) (string, bool) {
+	language := strings.ToLower(tag)
+
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+
+	_, ok := errorMessages[language]
+
+	return language, ok
+}
+
+// errorMessage returns the human readable message of an error code in the given language,
+// falling back to defaultLanguage and then to the code itself.
+func errorMessage(language string, code game.Error) string {
+	if message, ok := errorMessages[language][code]; ok {
+		return message
+	}
+
+	if message, ok := errorMessages[defaultLanguage][code]; ok {
+		return message
+	}
+
+	return string(code)
+}
+
+// internalErrors counts the internal errors to give each of them an opaque id.
+var internalErrors uint64
+
+// serverStart makes internal error ids unique across restarts.
+var serverStart = strconv.FormatInt(time.Now().Unix(), 36)
+
+// newNotifyError builds the error message for err, localised in the given language.
+// Only game errors are sent as they are: any other error is logged and reported
+// as game.InternalError along with the id it has been logged with.
+func newNotifyError(err error, reqID int, language string) data.NotifyError {
+	notify := data.NotifyError{
+		ReqID: reqID,
+	}
+
+	var detailed *game.DetailedError
+
+	if errors.As(err, &detailed) {
+		notify.Code = detailed.Err
+		notify.Details = &detailed.Details
+
+	} else if !errors.As(err, &notify.Code) {
+		notify.Code = game.InternalError
+		notify.ErrorID = serverStart + "-" + strconv.FormatUint(atomic.AddUint64(&internalErrors, 1), 36)
+
+		log.Println("internal error: id=", notify.ErrorID, "error=", err)
+	}
+
+	notify.Message = errorMessage(language, notify.Code)
+
+	return notify
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
 )
@@ -14,21 +13,37 @@ func (*CreateGameHandler) RequestType() data.MessageType {
 	return data.MessageCreateGameRequest
 }
 
-// BodyReader does nothing, create game request doesn't have a payload.
-func (*CreateGameHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	return nil, nil
+// NewBody returns an empty CreateGameRequest.
+func (*CreateGameHandler) NewBody() interface{} {
+	return &data.CreateGameRequest{}
+}
+
+// OptionalBody marks the payload as optional: legacy clients don't send it.
+func (*CreateGameHandler) OptionalBody() {}
+
+// SessionRequest marks the request as not about a game.
+func (*CreateGameHandler) SessionRequest() {}
+
+// Preconditions requires the user to be signed in.
+func (*CreateGameHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.SignedIn}
 }
 
 // Handle processes create game requests.
 func (*CreateGameHandler) Handle(server *web.Server, req *web.Request) {
-	g, player, err := server.NewGame(req.UserIO)
+	body := req.Body.(*data.CreateGameRequest)
+
+	g, player, err := server.NewGame(req.UserIO, body.Variant, body.Rules, body.Seed)
 
 	if err != nil {
 		req.SendError(err)
+		return
 	}
 
 	req.SendMessage(data.MessageCreateGameResponse, data.CreateGameResponse{
-		GameID: g.ID(),
-		MyID:   player.ID(),
+		GameID:  g.ID(),
+		Variant: g.Variant().Name,
+		Rules:   g.Rules(),
+		MyID:    player.ID(),
 	})
 }
@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -17,29 +14,21 @@ func (*DeclareSolutionHandler) RequestType() data.MessageType {
 	return data.MessageDeclareSolutionRequest
 }
 
-// BodyReader parses DeclareSolutionRequest json from ws.
-func (*DeclareSolutionHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.DeclareSolutionRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty DeclareSolutionRequest.
+func (*DeclareSolutionHandler) NewBody() interface{} {
+	return &data.DeclareSolutionRequest{}
+}
+
+// Preconditions requires the current player, once the query is over.
+func (*DeclareSolutionHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateTrySolution)}
 }
 
 // Handle processes declare solution requests.
 func (*DeclareSolutionHandler) Handle(server *web.Server, req *web.Request) {
-	declareSolution, ok := req.Body.(*data.DeclareSolutionRequest)
+	declareSolution := req.Body.(*data.DeclareSolutionRequest)
 
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting DeclareSolutionRequest, found", req.Body)
-		return
-	}
-
-	g, err := server.CheckCurrentPlayer(req)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
+	g := req.Game()
 
 	records, err := g.CheckSolution(declareSolution.Character, declareSolution.Room, declareSolution.Weapon)
 
@@ -51,9 +40,5 @@ func (*DeclareSolutionHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageEmptyResponse, nil)
 
-	for _, record := range records {
-		server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-			return record.AsMessageFor(player)
-		})
-	}
+	server.NotifyMoves(g, records...)
 }
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+)
+
+// All returns an instance of every request handler.
+func All() []web.RequestHandler {
+	return []web.RequestHandler{
+		&SignInHandler{},
+		&CreateGameHandler{},
+		&JoinGameHandler{},
+		&SelectCharHandler{},
+		&VoteStartHandler{},
+		&RollDicesHandler{},
+		&MoveHandler{},
+		&StopMovingHandler{},
+		&UsePassageHandler{},
+		&StayInRoomHandler{},
+		&PassHandler{},
+		&QuerySolutionHandler{},
+		&RevealHandler{},
+		&DeclareSolutionHandler{},
+		&SetAutoAnswerHandler{},
+	}
+}
@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
 )
@@ -16,23 +13,26 @@ func (*JoinGameHandler) RequestType() data.MessageType {
 	return data.MessageJoinGameRequest
 }
 
-// BodyReader parses JoinGameRequest json from ws.
-func (*JoinGameHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.JoinGameRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty JoinGameRequest.
+func (*JoinGameHandler) NewBody() interface{} {
+	return &data.JoinGameRequest{}
+}
+
+// BodyGameID returns the game to join: the request is served by its goroutine.
+func (*JoinGameHandler) BodyGameID(body interface{}) string {
+	return body.(*data.JoinGameRequest).GameID
+}
+
+// Preconditions requires the user to be signed in.
+func (*JoinGameHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.SignedIn}
 }
 
 // Handle processes join game requests.
 func (*JoinGameHandler) Handle(server *web.Server, req *web.Request) {
-	joinGame, ok := req.Body.(*data.JoinGameRequest)
-
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting JoinGameRequest, found", req.Body)
-		return
-	}
+	joinGame := req.Body.(*data.JoinGameRequest)
 
-	resp, err := server.JoinGame(joinGame.GameID, req.UserIO)
+	resp, err := server.JoinGame(req)
 
 	if err != nil {
 		req.SendError(err)
@@ -42,5 +42,5 @@ func (*JoinGameHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageJoinGameResponse, resp)
 
-	server.CompleteJoin(req.UserIO)
+	server.CompleteJoin(req, joinGame.LastSeq)
 }
@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -17,29 +14,21 @@ func (*MoveHandler) RequestType() data.MessageType {
 	return data.MessageMoveRequest
 }
 
-// BodyReader parses MoveRequest json from ws.
-func (*MoveHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.MoveRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty MoveRequest.
+func (*MoveHandler) NewBody() interface{} {
+	return &data.MoveRequest{}
+}
+
+// Preconditions requires the current player, after she/he rolled the dices.
+func (*MoveHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateMove)}
 }
 
 // Handle processes move requests.
 func (*MoveHandler) Handle(server *web.Server, req *web.Request) {
-	move, ok := req.Body.(*data.MoveRequest)
-
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting MoveRequest, found", req.Body)
-		return
-	}
+	move := req.Body.(*data.MoveRequest)
 
-	g, err := server.CheckStartedGame(req.UserIO)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
+	g := req.Game()
 
 	record, err := g.Move(move.EnterRoom, move.MapX, move.MapY)
 
@@ -51,7 +40,5 @@ func (*MoveHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageEmptyResponse, nil)
 
-	server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-		return record.AsMessageFor(player)
-	})
+	server.NotifyMoves(g, record)
 }
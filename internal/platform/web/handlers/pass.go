@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -15,20 +14,19 @@ func (*PassHandler) RequestType() data.MessageType {
 	return data.MessagePassRequest
 }
 
-// BodyReader does nothing, pass request doesn't have a payload.
-func (*PassHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	return nil, nil
+// NewBody returns nil, pass request doesn't have a payload.
+func (*PassHandler) NewBody() interface{} {
+	return nil
+}
+
+// Preconditions requires the current player, after she/he moved.
+func (*PassHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateQuery, game.GameStateTrySolution)}
 }
 
 // Handle processes pass requests.
 func (*PassHandler) Handle(server *web.Server, req *web.Request) {
-	g, err := server.CheckCurrentPlayer(req)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
+	g := req.Game()
 
 	record, err := g.Pass()
 
@@ -40,7 +38,5 @@ func (*PassHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageEmptyResponse, nil)
 
-	server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-		return record.AsMessageFor(player)
-	})
+	server.NotifyMoves(g, record)
 }
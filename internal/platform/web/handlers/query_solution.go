@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -17,31 +14,23 @@ func (*QuerySolutionHandler) RequestType() data.MessageType {
 	return data.MessageQuerySolutionRequest
 }
 
-// BodyReader parses QuerySolutionRequest json from ws.
-func (*QuerySolutionHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.QuerySolutionRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty QuerySolutionRequest.
+func (*QuerySolutionHandler) NewBody() interface{} {
+	return &data.QuerySolutionRequest{}
+}
+
+// Preconditions requires the current player, before anyone answered.
+func (*QuerySolutionHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateQuery)}
 }
 
 // Handle processes query solution requests.
 func (*QuerySolutionHandler) Handle(server *web.Server, req *web.Request) {
-	querySolution, ok := req.Body.(*data.QuerySolutionRequest)
+	querySolution := req.Body.(*data.QuerySolutionRequest)
 
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting QuerySolutionRequest, found", req.Body)
-		return
-	}
+	g := req.Game()
 
-	g, err := server.CheckCurrentPlayer(req)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
-
-	record, err := g.QuerySolution(querySolution.Character, querySolution.Weapon)
+	records, err := g.QuerySolution(querySolution.Character, querySolution.Weapon)
 
 	if err != nil {
 		req.SendError(err)
@@ -51,7 +40,7 @@ func (*QuerySolutionHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageEmptyResponse, nil)
 
-	server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-		return record.AsMessageFor(player)
-	})
+	server.NotifyMoves(g, records...)
+
+	server.NotifyAnswerOptions(g)
 }
@@ -1,11 +1,7 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
-	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
 )
 
@@ -17,31 +13,23 @@ func (*RevealHandler) RequestType() data.MessageType {
 	return data.MessageRevealRequest
 }
 
-// BodyReader parses RevealRequest json from ws.
-func (*RevealHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.RevealRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty RevealRequest.
+func (*RevealHandler) NewBody() interface{} {
+	return &data.RevealRequest{}
+}
+
+// Preconditions requires the player due to answer the query.
+func (*RevealHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.AnsweringPlayer}
 }
 
 // Handle processes reveal requests.
 func (*RevealHandler) Handle(server *web.Server, req *web.Request) {
-	reveal, ok := req.Body.(*data.RevealRequest)
+	reveal := req.Body.(*data.RevealRequest)
 
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting RevealRequest, found", req.Body)
-		return
-	}
+	g := req.Game()
 
-	g, err := server.CheckAnsweringPlayer(req)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
-
-	record, err := g.Reveal(reveal.Card)
+	records, err := g.Reveal(reveal.Card)
 
 	if err != nil {
 		req.SendError(err)
@@ -51,7 +39,7 @@ func (*RevealHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageEmptyResponse, nil)
 
-	server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-		return record.AsMessageFor(player)
-	})
+	server.NotifyMoves(g, records...)
+
+	server.NotifyAnswerOptions(g)
 }
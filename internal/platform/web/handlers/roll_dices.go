@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -15,20 +14,19 @@ func (*RollDicesHandler) RequestType() data.MessageType {
 	return data.MessageRollDicesRequest
 }
 
-// BodyReader does nothing: roll dices request does not a payload.
-func (*RollDicesHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	return nil, nil
+// NewBody returns nil: roll dices request does not a payload.
+func (*RollDicesHandler) NewBody() interface{} {
+	return nil
+}
+
+// Preconditions requires the current player at the beginning of her/his turn.
+func (*RollDicesHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateNewTurn)}
 }
 
 // Handle processes roll dices requests.
 func (*RollDicesHandler) Handle(server *web.Server, req *web.Request) {
-	g, err := server.CheckCurrentPlayer(req)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
+	g := req.Game()
 
 	record, err := g.RollDices()
 
@@ -40,7 +38,5 @@ func (*RollDicesHandler) Handle(server *web.Server, req *web.Request) {
 
 	req.SendMessage(data.MessageEmptyResponse, nil)
 
-	server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-		return record.AsMessageFor(player)
-	})
+	server.NotifyMoves(g, record)
 }
@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -17,31 +14,23 @@ func (*SelectCharHandler) RequestType() data.MessageType {
 	return data.MessageSelectCharRequest
 }
 
-// BodyReader parses SelectCharacterRequest json from ws.
-func (*SelectCharHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.SelectCharacterRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty SelectCharacterRequest.
+func (*SelectCharHandler) NewBody() interface{} {
+	return &data.SelectCharacterRequest{}
+}
+
+// Preconditions requires a player of the game, the game itself rejects the request once started.
+func (*SelectCharHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.InGame}
 }
 
 // Handle processes select char requests.
 func (*SelectCharHandler) Handle(server *web.Server, req *web.Request) {
-	selectCharacter, ok := req.Body.(*data.SelectCharacterRequest)
+	selectCharacter := req.Body.(*data.SelectCharacterRequest)
 
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting SelectCharacterRequest, found", req.Body)
-		return
-	}
+	g := req.Game()
 
-	g, err := server.CheckStartedGame(req.UserIO)
-
-	if err != nil {
-		req.SendError(err)
-
-		return
-	}
-
-	newUserState, err := server.SelectCharacter(req.UserIO, selectCharacter.Character)
+	newUserState, err := server.SelectCharacter(req, selectCharacter.Character)
 
 	if err != nil {
 		req.SendError(err)
@@ -50,6 +39,9 @@ func (*SelectCharHandler) Handle(server *web.Server, req *web.Request) {
 	}
 
 	if newUserState == nil {
+		// already selected: nothing changed
+		req.SendMessage(data.MessageEmptyResponse, nil)
+
 		return
 	}
 
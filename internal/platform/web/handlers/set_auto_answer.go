@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+)
+
+// SetAutoAnswerHandler handles set auto answer requests.
+type SetAutoAnswerHandler struct{}
+
+// RequestType returns Set Auto Answer Request identifier.
+func (*SetAutoAnswerHandler) RequestType() data.MessageType {
+	return data.MessageSetAutoAnswerRequest
+}
+
+// NewBody returns an empty SetAutoAnswerRequest.
+func (*SetAutoAnswerHandler) NewBody() interface{} {
+	return &data.SetAutoAnswerRequest{}
+}
+
+// Preconditions requires a player of the game, in any state.
+func (*SetAutoAnswerHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.InGame}
+}
+
+// Handle processes set auto answer requests.
+func (*SetAutoAnswerHandler) Handle(server *web.Server, req *web.Request) {
+	setAutoAnswer := req.Body.(*data.SetAutoAnswerRequest)
+
+	g := req.Game()
+	records, err := server.SetAutoAnswer(req, setAutoAnswer.AutoAnswer)
+
+	if err != nil {
+		req.SendError(err)
+
+		return
+	}
+
+	req.SendMessage(data.MessageEmptyResponse, nil)
+
+	server.NotifyMoves(g, records...)
+
+	if len(records) > 0 {
+		server.NotifyAnswerOptions(g)
+	}
+}
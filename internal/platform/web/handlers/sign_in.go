@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"log"
-
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
 )
@@ -16,27 +13,30 @@ func (*SignInHandler) RequestType() data.MessageType {
 	return data.MessageSignInRequest
 }
 
-// BodyReader parses SignInRequest json from ws.
-func (*SignInHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.SignInRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty SignInRequest.
+func (*SignInHandler) NewBody() interface{} {
+	return &data.SignInRequest{}
+}
+
+// SessionRequest marks the request as not about a game.
+func (*SignInHandler) SessionRequest() {}
+
+// Preconditions returns none: anyone can sign in.
+func (*SignInHandler) Preconditions() []web.Precondition {
+	return nil
 }
 
 // Handle processes sign in requests.
 func (*SignInHandler) Handle(server *web.Server, req *web.Request) {
-	signIn, ok := req.Body.(*data.SignInRequest)
-
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting SignInRequest, found", req.Body)
-		return
-	}
+	signIn := req.Body.(*data.SignInRequest)
 
 	if signIn.Token == "" {
 		// this is a new user: generate a new token and return it
 
 		user, token := server.SignIn(req.UserIO, signIn.Name)
 
+		server.SetLanguage(user, signIn.Language)
+
 		req.SendMessage(data.MessageSignInResponse, data.SignInResponse{
 			Token:        token,
 			RunningGames: server.RunningGames(user),
@@ -54,6 +54,8 @@ func (*SignInHandler) Handle(server *web.Server, req *web.Request) {
 		return
 	}
 
+	server.SetLanguage(user, signIn.Language)
+
 	req.SendMessage(data.MessageSignInResponse, data.SignInResponse{
 		RunningGames: server.RunningGames(user),
 	})
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+)
+
+// StayInRoomHandler handles stay in room requests.
+type StayInRoomHandler struct{}
+
+// RequestType returns Stay In Room Request identifier.
+func (*StayInRoomHandler) RequestType() data.MessageType {
+	return data.MessageStayInRoomRequest
+}
+
+// NewBody returns nil, stay in room request doesn't have a payload.
+func (*StayInRoomHandler) NewBody() interface{} {
+	return nil
+}
+
+// Preconditions requires the current player at the beginning of her/his turn.
+func (*StayInRoomHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateNewTurn)}
+}
+
+// Handle processes stay in room requests.
+func (*StayInRoomHandler) Handle(server *web.Server, req *web.Request) {
+	g := req.Game()
+
+	record, err := g.StayInRoom()
+
+	if err != nil {
+		req.SendError(err)
+
+		return
+	}
+
+	req.SendMessage(data.MessageEmptyResponse, nil)
+
+	server.NotifyMoves(g, record)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+)
+
+// StopMovingHandler handles stop moving requests.
+type StopMovingHandler struct{}
+
+// RequestType returns Stop Moving Request identifier.
+func (*StopMovingHandler) RequestType() data.MessageType {
+	return data.MessageStopMovingRequest
+}
+
+// NewBody returns nil, stop moving request doesn't have a payload.
+func (*StopMovingHandler) NewBody() interface{} {
+	return nil
+}
+
+// Preconditions requires the current player, after she/he rolled the dices.
+func (*StopMovingHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateMove)}
+}
+
+// Handle processes stop moving requests.
+func (*StopMovingHandler) Handle(server *web.Server, req *web.Request) {
+	g := req.Game()
+
+	record, err := g.StopMoving()
+
+	if err != nil {
+		req.SendError(err)
+
+		return
+	}
+
+	req.SendMessage(data.MessageEmptyResponse, nil)
+
+	server.NotifyMoves(g, record)
+}
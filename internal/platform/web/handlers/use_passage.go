@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+)
+
+// UsePassageHandler handles use passage requests.
+type UsePassageHandler struct{}
+
+// RequestType returns Use Passage Request identifier.
+func (*UsePassageHandler) RequestType() data.MessageType {
+	return data.MessageUsePassageRequest
+}
+
+// NewBody returns an empty UsePassageRequest.
+func (*UsePassageHandler) NewBody() interface{} {
+	return &data.UsePassageRequest{}
+}
+
+// Preconditions requires the current player at the beginning of her/his turn.
+func (*UsePassageHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.CurrentPlayer, web.InStates(game.GameStateNewTurn)}
+}
+
+// Handle processes use passage requests.
+func (*UsePassageHandler) Handle(server *web.Server, req *web.Request) {
+	passage := req.Body.(*data.UsePassageRequest)
+
+	g := req.Game()
+
+	record, err := g.UsePassage(passage.Room)
+
+	if err != nil {
+		req.SendError(err)
+
+		return
+	}
+
+	req.SendMessage(data.MessageEmptyResponse, nil)
+
+	server.NotifyMoves(g, record)
+}
@@ -1,10 +1,8 @@
 package handlers
 
 import (
-	"log"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
 	"github.com/makeroo/my_clue_be/internal/platform/web"
@@ -18,23 +16,21 @@ func (*VoteStartHandler) RequestType() data.MessageType {
 	return data.MessageVoteStartRequest
 }
 
-// BodyReader parses VoteStartRequest json from ws.
-func (*VoteStartHandler) BodyReader(ws *websocket.Conn) (interface{}, error) {
-	body := data.VoteStartRequest{}
-	err := ws.ReadJSON(&body)
-	return &body, err
+// NewBody returns an empty VoteStartRequest.
+func (*VoteStartHandler) NewBody() interface{} {
+	return &data.VoteStartRequest{}
+}
+
+// Preconditions requires a player of the game, the game itself rejects the request once started.
+func (*VoteStartHandler) Preconditions() []web.Precondition {
+	return []web.Precondition{web.InGame}
 }
 
 // Handle processes vote start requests.
 func (*VoteStartHandler) Handle(server *web.Server, req *web.Request) {
-	voteStart, ok := req.Body.(*data.VoteStartRequest)
+	voteStart := req.Body.(*data.VoteStartRequest)
 
-	if !ok {
-		log.Println("ERROR request type mismatch, expecting VoteStartRequest, found", req.Body)
-		return
-	}
-
-	g, err := server.VoteStart(req.UserIO, voteStart.Vote)
+	g, err := server.VoteStart(req, voteStart.Vote)
 
 	if err != nil {
 		req.SendError(err)
@@ -48,21 +44,12 @@ func (*VoteStartHandler) Handle(server *web.Server, req *web.Request) {
 		return
 	}
 
-	turnSequence := g.PlayerTurnSequence()
-
-	server.NotifyPlayers(g, nil, data.MessageNotifyGameStarted, func(player *game.Player) interface{} {
-		return data.NotifyGameStarted{
-			PlayersOrder: turnSequence,
-			Deck:         player.Deck(),
-		}
-	})
+	server.NotifyGameStarted(g)
 
-	server.NotifyPlayers(g, nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
-		return game.MoveRecord{
-			PlayerID:   g.CurrentPlayer().ID(),
-			Timestamp:  time.Now(),
-			Move:       &game.StartMove{},
-			StateDelta: g.StartState(),
-		}
+	server.NotifyMoves(g, &game.MoveRecord{
+		PlayerID:   g.CurrentPlayer().ID(),
+		Timestamp:  time.Now(),
+		Move:       &game.StartMove{},
+		StateDelta: g.StartState(),
 	})
 }
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// journalEntry is a notification sent to the players of a game.
+// The message builder is kept instead of the message body because
+// each player can receive a different body (eg. revealed cards).
+type journalEntry struct {
+	seq         int
+	messageType data.MessageType
+	skipPlayer  *game.Player
+	// target, if defined, is the only player the notification is sent to.
+	target         *game.Player
+	messageBuilder func(player *game.Player) interface{}
+}
+
+// sentTo returns true if the notification is for the player.
+func (entry *journalEntry) sentTo(player *game.Player) bool {
+	return player != entry.skipPlayer && (entry.target == nil || entry.target == player)
+}
+
+// journal keeps the most recent notifications of a game in a ring buffer.
+type journal struct {
+	entries []journalEntry
+	// head is the index of the oldest entry, len the number of entries.
+	head int
+	len  int
+}
+
+func newJournal(size int) journal {
+	if size < 0 {
+		size = 0
+	}
+
+	return journal{
+		entries: make([]journalEntry, size),
+	}
+}
+
+// record appends a notification to the journal dropping the oldest one if
+// the journal is full.
+func (j *journal) record(entry journalEntry) {
+	if len(j.entries) == 0 {
+		return
+	}
+
+	if j.len < len(j.entries) {
+		j.entries[(j.head+j.len)%len(j.entries)] = entry
+		j.len++
+
+		return
+	}
+
+	j.entries[j.head] = entry
+	j.head = (j.head + 1) % len(j.entries)
+}
+
+// oldest returns the sequence number of the oldest notification kept, 0 if none.
+func (j *journal) oldest() int {
+	if j.len == 0 {
+		return 0
+	}
+
+	return j.entries[j.head].seq
+}
+
+// each calls f on every notification kept, from the oldest one.
+func (j *journal) each(f func(entry *journalEntry)) {
+	for i := 0; i < j.len; i++ {
+		f(&j.entries[(j.head+i)%len(j.entries)])
+	}
+}
+
+// canReplay returns true if all the notifications following lastSeq are still in the journal.
+func (g *serverGame) canReplay(lastSeq int) bool {
+	if lastSeq > g.seq {
+		// the client knows of notifications this game never sent, eg. server restarted
+		return false
+	}
+
+	if lastSeq == g.seq {
+		return true
+	}
+
+	oldest := g.journal.oldest()
+
+	return oldest > 0 && oldest <= lastSeq+1
+}
+
+// replay resends to a player all the notifications following lastSeq.
+func (g *serverGame) replay(gu *gameUser, lastSeq int) {
+	g.journal.each(func(entry *journalEntry) {
+		if entry.seq <= lastSeq || !entry.sentTo(gu.player) {
+			return
+		}
+
+		gu.io.notify(data.MessageFrame{
+			Header: data.MessageHeader{
+				Type:   entry.messageType,
+				GameID: g.game.ID(),
+				Seq:    entry.seq,
+			},
+			Body: entry.messageBuilder(gu.player),
+		})
+	})
+}
+
+// fullState builds the game state as seen by a player.
+func (g *serverGame) fullState(gu *gameUser) data.NotifyFullState {
+	players := make([]data.NotifyUserState, len(g.players))
+
+	for i, p := range g.players {
+		players[i] = p.State()
+	}
+
+	r := data.NotifyFullState{
+		Players: players,
+		Game:    g.game.FullState(gu.player.ID()),
+	}
+
+	if g.game.Started() {
+		r.NotifyGameStarted = GameStartedNotification(g.game, gu.player)
+	}
+
+	return r
+}
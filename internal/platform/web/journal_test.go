@@ -0,0 +1,184 @@
+package web
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+func TestJournal(t *testing.T) {
+	tests := []struct {
+		size, recorded int
+		// kept are the sequence numbers in the journal, from the oldest one
+		kept []int
+	}{
+		{0, 3, nil},
+		{3, 0, nil},
+		{3, 2, []int{1, 2}},
+		{3, 3, []int{1, 2, 3}},
+		{3, 5, []int{3, 4, 5}},
+		{3, 7, []int{5, 6, 7}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("size %d recorded %d", test.size, test.recorded), func(t *testing.T) {
+			j := newJournal(test.size)
+
+			for seq := 1; seq <= test.recorded; seq++ {
+				j.record(journalEntry{seq: seq})
+			}
+
+			var kept []int
+
+			j.each(func(entry *journalEntry) {
+				kept = append(kept, entry.seq)
+			})
+
+			if !reflect.DeepEqual(kept, test.kept) {
+				t.Errorf("kept %v, want %v", kept, test.kept)
+			}
+
+			oldest := 0
+
+			if len(test.kept) > 0 {
+				oldest = test.kept[0]
+			}
+
+			if j.oldest() != oldest {
+				t.Errorf("oldest %d, want %d", j.oldest(), oldest)
+			}
+		})
+	}
+}
+
+// newTestGame builds a table of players without starting its goroutine: the tests call its
+// methods as the game goroutine would. Each player follows the game through a ws that is
+// not connected, see queued.
+func newTestGame(journalSize int, players ...string) (*Server, *serverGame) {
+	server := New(&websocket.Upgrader{}, rand.New(rand.NewSource(1)))
+
+	sg := &serverGame{
+		game:     game.New("g1", game.Classic, game.Rules{}, game.SeedFromID(1)),
+		registry: &server.registry,
+		bus:      server.bus,
+		journal:  newJournal(journalSize),
+	}
+
+	server.registry.games[sg.game.ID()] = sg
+
+	for _, name := range players {
+		player, err := sg.game.AddPlayer()
+
+		if err != nil {
+			panic(err)
+		}
+
+		userIO := &UserIO{
+			registry: &server.registry,
+			outbox:   newOutbox(64, OverflowResync),
+			closed:   make(chan struct{}),
+			games:    make(map[string]*gameUser),
+		}
+
+		gu := &gameUser{
+			user:   &User{name: name, io: []*UserIO{userIO}},
+			io:     userIO,
+			player: player,
+			game:   sg,
+		}
+
+		gu.user.joinedGames = append(gu.user.joinedGames, gu)
+		userIO.user = gu.user
+		userIO.games[sg.game.ID()] = gu
+		sg.players = append(sg.players, gu)
+	}
+
+	return server, sg
+}
+
+// queued empties the outbox of a ws returning the messages it held.
+func queued(userIO *UserIO) []data.MessageFrame {
+	var frames []data.MessageFrame
+
+	for {
+		frame, ok, _ := userIO.outbox.pop()
+
+		if !ok {
+			return frames
+		}
+
+		frames = append(frames, frame)
+	}
+}
+
+func TestCompleteJoin(t *testing.T) {
+	const notifications = 5
+
+	tests := []struct {
+		name        string
+		journalSize int
+		lastSeq     int
+		// replayed are the sequence numbers sent again, nil if the full state is sent instead
+		replayed []int
+	}{
+		{"up to date", 3, 5, []int{}},
+		{"missed some", 3, 3, []int{4, 5}},
+		{"missed all the kept ones", 3, 2, []int{3, 4, 5}},
+		{"missed too many", 3, 1, nil},
+		{"unknown seq", 3, 9, nil},
+		{"no journal", 0, 4, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, sg := newTestGame(test.journalSize, "alice", "bob")
+
+			bob := sg.players[1]
+			userIO := bob.io
+			bob.io = nil
+
+			for i := 0; i < notifications; i++ {
+				sg.notifyPlayers(nil, data.MessageNotifyUserState, func(player *game.Player) interface{} {
+					return sg.players[0].State()
+				})
+			}
+
+			bob.io = userIO
+
+			server.CompleteJoin(&Request{UserIO: userIO, gameUser: bob}, test.lastSeq)
+
+			frames := queued(userIO)
+
+			if test.replayed == nil {
+				if len(frames) != 1 || frames[0].Header.Type != data.MessageNotifyFullState || frames[0].Header.Seq != notifications {
+					t.Fatalf("sent %v, want the full state with seq %d", frames, notifications)
+				}
+
+				if _, ok := frames[0].Body.(data.NotifyFullState); !ok {
+					t.Errorf("full state body %T", frames[0].Body)
+				}
+
+				return
+			}
+
+			replayed := []int{}
+
+			for _, frame := range frames {
+				if frame.Header.Type != data.MessageNotifyUserState {
+					t.Errorf("replayed %s, want %s", frame.Header.Type, data.MessageNotifyUserState)
+				}
+
+				replayed = append(replayed, frame.Header.Seq)
+			}
+
+			if !reflect.DeepEqual(replayed, test.replayed) {
+				t.Errorf("replayed %v, want %v", replayed, test.replayed)
+			}
+		})
+	}
+}
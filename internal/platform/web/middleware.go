@@ -0,0 +1,145 @@
+package web
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"math"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// HandlerFunc serves a request.
+type HandlerFunc func(*Server, *Request)
+
+// Middleware wraps the handling of every request, see Server.Use.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Recover sends back an internal error, and logs it, if the request handler panics.
+func Recover(next HandlerFunc) HandlerFunc {
+	return func(server *Server, req *Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				req.SendError(fmt.Errorf("panic serving %s: %v\n%s", req.Type, r, debug.Stack()))
+			}
+		}()
+
+		next(server, req)
+	}
+}
+
+// Logger logs every request, the time spent serving it and the error sent back, if any.
+func Logger(next HandlerFunc) HandlerFunc {
+	return func(server *Server, req *Request) {
+		start := time.Now()
+
+		next(server, req)
+
+		log.Println("served: type=", req.Type, "req=", req.ReqID, "game=", req.GameID, "time=", time.Since(start), "error=", req.ErrorCode())
+	}
+}
+
+// Metrics counts the requests and the nanoseconds spent serving them by request type,
+// and the errors sent back by code. The maps are usually published with expvar.NewMap.
+func Metrics(requests, nanoseconds, errors *expvar.Map) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(server *Server, req *Request) {
+			start := time.Now()
+
+			next(server, req)
+
+			requests.Add(string(req.Type), 1)
+			nanoseconds.Add(string(req.Type), int64(time.Since(start)))
+
+			if code := req.ErrorCode(); code != "" {
+				errors.Add(string(code), 1)
+			}
+		}
+	}
+}
+
+// bucket is the token bucket of a websocket, see RateLimit.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimit rejects with game.TooManyRequests the requests of a websocket exceeding perSecond
+// requests per second on average, allowing bursts of burst requests.
+func RateLimit(perSecond float64, burst int) Middleware {
+	var mu sync.Mutex
+
+	buckets := make(map[*UserIO]*bucket)
+	lastSweep := time.Now()
+
+	// refill returns the tokens of a bucket at the given time.
+	refill := func(b *bucket, now time.Time) float64 {
+		return math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(server *Server, req *Request) {
+			mu.Lock()
+
+			now := time.Now()
+
+			if now.Sub(lastSweep) > time.Minute {
+				// full buckets are as good as new ones: forget them, and the closed websockets with them
+				for userIO, b := range buckets {
+					if refill(b, now) >= float64(burst) {
+						delete(buckets, userIO)
+					}
+				}
+
+				lastSweep = now
+			}
+
+			b := buckets[req.UserIO]
+
+			if b == nil {
+				b = &bucket{tokens: float64(burst), last: now}
+				buckets[req.UserIO] = b
+			}
+
+			b.tokens = refill(b, now)
+			b.last = now
+
+			limited := b.tokens < 1
+
+			if !limited {
+				b.tokens--
+			}
+
+			mu.Unlock()
+
+			if limited {
+				req.SendError(game.TooManyRequests)
+
+				return
+			}
+
+			next(server, req)
+		}
+	}
+}
+
+// serveRequest is the innermost HandlerFunc: it checks the request handler
+// preconditions and then executes it.
+func serveRequest(server *Server, req *Request) {
+	if req.err != nil {
+		req.SendError(req.err)
+
+		return
+	}
+
+	if err := checkPreconditions(req); err != nil {
+		req.SendError(err)
+
+		return
+	}
+
+	req.handler.Handle(server, req)
+}
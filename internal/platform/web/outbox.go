@@ -0,0 +1,196 @@
+package web
+
+import (
+	"sync"
+
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+)
+
+// OverflowPolicy tells what to do when a client is too slow to keep up with its messages
+// and its outbound queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDisconnect closes the connection: the client is expected to reconnect
+	// and to join its games back.
+	OverflowDisconnect OverflowPolicy = "disconnect"
+	// OverflowResync drops the queued notifications of a game and, once the queue has drained,
+	// sends the full state of the game instead. Responses are never dropped: if they fill the
+	// queue the connection is closed.
+	OverflowResync OverflowPolicy = "resync"
+)
+
+// queuedFrame is a message waiting to be written to the ws.
+type queuedFrame struct {
+	data.MessageFrame
+	// notification is true if the message is not the response to a request, so it can
+	// be replaced by a full state of its game.
+	notification bool
+}
+
+// outbox is the bounded outbound queue of a ws.
+// The game and websocket goroutines push messages, the write pump pops them.
+type outbox struct {
+	mu       sync.Mutex
+	frames   []queuedFrame
+	capacity int
+	policy   OverflowPolicy
+
+	// resync are the games whose notifications are dropped until their full state is queued,
+	// the value is true once the full state has been requested to the game goroutine.
+	resync map[string]bool
+	// closing is true once the queue overflowed and the ws has to be closed.
+	closing bool
+	// maxDepth is the highest number of messages queued so far.
+	maxDepth int
+
+	// ready signals the write pump that there is something to do.
+	ready chan struct{}
+}
+
+func newOutbox(capacity int, policy OverflowPolicy) *outbox {
+	return &outbox{
+		capacity: capacity,
+		policy:   policy,
+		resync:   make(map[string]bool),
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+// push queues a message. It returns false if the queue overflowed and the ws has to be closed.
+func (o *outbox) push(frame queuedFrame) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closing {
+		return true
+	}
+
+	gameID := frame.Header.GameID
+
+	if frame.notification {
+		if _, ok := o.resync[gameID]; ok {
+			// the full state to come includes it
+			return true
+		}
+	}
+
+	if len(o.frames) >= o.capacity && o.policy == OverflowResync {
+		o.collapse()
+
+		if frame.notification {
+			o.resync[gameID] = false
+			o.signal()
+
+			return true
+		}
+	}
+
+	if len(o.frames) >= o.capacity {
+		o.closing = true
+		o.frames = nil
+		o.signal()
+
+		return false
+	}
+
+	o.frames = append(o.frames, frame)
+
+	if len(o.frames) > o.maxDepth {
+		o.maxDepth = len(o.frames)
+	}
+
+	o.signal()
+
+	return true
+}
+
+// collapse drops the queued notifications, their games will be sent in full instead.
+// mu must be held.
+func (o *outbox) collapse() {
+	kept := o.frames[:0]
+
+	for _, frame := range o.frames {
+		if frame.notification {
+			if _, ok := o.resync[frame.Header.GameID]; !ok {
+				o.resync[frame.Header.GameID] = false
+			}
+
+			continue
+		}
+
+		kept = append(kept, frame)
+	}
+
+	for i := len(kept); i < len(o.frames); i++ {
+		o.frames[i] = queuedFrame{}
+	}
+
+	o.frames = kept
+}
+
+// signal wakes the write pump up. mu must be held.
+func (o *outbox) signal() {
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the oldest queued message, if any. When the queue is empty it returns the games
+// whose full state has to be requested instead, they are returned only once.
+func (o *outbox) pop() (frame data.MessageFrame, ok bool, resync []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.frames) > 0 {
+		frame = o.frames[0].MessageFrame
+		o.frames[0] = queuedFrame{}
+		o.frames = o.frames[1:]
+
+		return frame, true, nil
+	}
+
+	for gameID, requested := range o.resync {
+		if !requested {
+			o.resync[gameID] = true
+			resync = append(resync, gameID)
+		}
+	}
+
+	return frame, false, resync
+}
+
+// resynced queues the full state of a game, if any, and stops dropping the game notifications.
+// It must be called by the game goroutine so that no notification is lost in between.
+func (o *outbox) resynced(gameID string, fullState *data.MessageFrame) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.resync, gameID)
+
+	if fullState == nil || o.closing {
+		return
+	}
+
+	// the queue may be full of responses: a full state is worth an extra slot
+	o.frames = append(o.frames, queuedFrame{MessageFrame: *fullState, notification: true})
+
+	o.signal()
+}
+
+// isClosing returns true if the queue overflowed and the ws has to be closed.
+func (o *outbox) isClosing() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.closing
+}
+
+// depth returns the number of messages queued and the highest number ever queued.
+func (o *outbox) depth() (int, int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.frames), o.maxDepth
+}
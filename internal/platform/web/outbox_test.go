@@ -0,0 +1,155 @@
+package web
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+)
+
+func response(reqID int) queuedFrame {
+	return queuedFrame{
+		MessageFrame: data.MessageFrame{Header: data.MessageHeader{Type: data.MessageEmptyResponse, ReqID: reqID}},
+	}
+}
+
+func notification(gameID string, seq int) queuedFrame {
+	return queuedFrame{
+		MessageFrame: data.MessageFrame{Header: data.MessageHeader{Type: data.MessageNotifyMoveRecord, GameID: gameID, Seq: seq}},
+		notification: true,
+	}
+}
+
+// popAll empties an outbox returning the messages it held and the games to resync.
+func popAll(o *outbox) ([]data.MessageHeader, []string) {
+	var headers []data.MessageHeader
+
+	for {
+		frame, ok, resync := o.pop()
+
+		if !ok {
+			sort.Strings(resync)
+
+			return headers, resync
+		}
+
+		headers = append(headers, frame.Header)
+	}
+}
+
+func TestOutboxOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		pushed []queuedFrame
+		// closing is true if the last push overflowed and the ws has to be closed
+		closing bool
+		sent    []data.MessageHeader
+		resync  []string
+	}{
+		{
+			name:   "resync collapses the notifications",
+			policy: OverflowResync,
+			pushed: []queuedFrame{notification("g1", 1), response(1), notification("g2", 1)},
+			sent:   []data.MessageHeader{response(1).Header},
+			resync: []string{"g1", "g2"},
+		},
+		{
+			name:   "resync drops the notifications of the games to resync",
+			policy: OverflowResync,
+			pushed: []queuedFrame{notification("g1", 1), notification("g1", 2), notification("g1", 3), notification("g1", 4), notification("g2", 1)},
+			sent:   []data.MessageHeader{notification("g2", 1).Header},
+			resync: []string{"g1"},
+		},
+		{
+			name:   "resync keeps the responses",
+			policy: OverflowResync,
+			pushed: []queuedFrame{notification("g1", 1), notification("g1", 2), response(1), response(2)},
+			sent:   []data.MessageHeader{response(1).Header, response(2).Header},
+			resync: []string{"g1"},
+		},
+		{
+			name:    "resync closes when the responses overflow",
+			policy:  OverflowResync,
+			pushed:  []queuedFrame{response(1), response(2), response(3)},
+			closing: true,
+		},
+		{
+			name:    "disconnect",
+			policy:  OverflowDisconnect,
+			pushed:  []queuedFrame{response(1), notification("g1", 1), notification("g1", 2)},
+			closing: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := newOutbox(2, test.policy)
+
+			for i, frame := range test.pushed {
+				ok := o.push(frame)
+
+				if want := !test.closing || i < len(test.pushed)-1; ok != want {
+					t.Errorf("push %d returned %v, want %v", i, ok, want)
+				}
+			}
+
+			if o.isClosing() != test.closing {
+				t.Errorf("closing %v, want %v", o.isClosing(), test.closing)
+			}
+
+			sent, resync := popAll(o)
+
+			if !reflect.DeepEqual(sent, test.sent) || !reflect.DeepEqual(resync, test.resync) {
+				t.Errorf("sent %v and resync %v, want %v and %v", sent, resync, test.sent, test.resync)
+			}
+
+			// the games to resync are returned once
+			if _, ok, resync := o.pop(); ok || resync != nil {
+				t.Errorf("popped %v and resync %v after emptying the queue", ok, resync)
+			}
+		})
+	}
+}
+
+func TestOutboxResynced(t *testing.T) {
+	o := newOutbox(2, OverflowResync)
+
+	for _, gameID := range []string{"g1", "g2"} {
+		for seq := 1; seq <= 3; seq++ {
+			o.push(notification(gameID, seq))
+		}
+	}
+
+	if _, resync := popAll(o); !reflect.DeepEqual(resync, []string{"g1", "g2"}) {
+		t.Fatalf("resync %v, want g1 and g2", resync)
+	}
+
+	// g1 notifications are dropped until its full state is queued
+	o.push(notification("g1", 4))
+
+	fullState := data.MessageFrame{Header: data.MessageHeader{Type: data.MessageNotifyFullState, GameID: "g1", Seq: 4}}
+
+	o.resynced("g1", &fullState)
+	o.push(notification("g1", 5))
+
+	// g2 is no longer followed: there is no full state to send
+	o.resynced("g2", nil)
+
+	sent, resync := popAll(o)
+
+	if want := []data.MessageHeader{fullState.Header, notification("g1", 5).Header}; !reflect.DeepEqual(sent, want) || resync != nil {
+		t.Errorf("sent %v and resync %v, want %v", sent, resync, want)
+	}
+
+	o.push(notification("g2", 4))
+
+	if sent, _ := popAll(o); !reflect.DeepEqual(sent, []data.MessageHeader{notification("g2", 4).Header}) {
+		t.Errorf("sent %v, want g2 notification 4", sent)
+	}
+
+	if _, maxDepth := o.depth(); maxDepth != 2 {
+		t.Errorf("max depth %d, want 2", maxDepth)
+	}
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"github.com/makeroo/my_clue_be/internal/platform/game"
+)
+
+// Precondition is a check a request has to pass before being handled.
+// It returns the error sent back to the user when the check fails.
+type Precondition func(*Request) error
+
+// SignedIn requires the user to be signed in.
+func SignedIn(req *Request) error {
+	if req.UserIO.registry.signedUser(req.UserIO) == nil {
+		return game.NotSignedIn
+	}
+
+	return nil
+}
+
+// InGame requires the user to be playing the game the request refers to.
+func InGame(req *Request) error {
+	if err := SignedIn(req); err != nil {
+		return err
+	}
+
+	if req.gameUser == nil {
+		return game.NotPlaying
+	}
+
+	return nil
+}
+
+// CurrentPlayer requires the user to be the current player of the game.
+func CurrentPlayer(req *Request) error {
+	if err := InGame(req); err != nil {
+		return err
+	}
+
+	if req.Game().CurrentPlayer() != req.Player() {
+		return game.NotYourTurn
+	}
+
+	return nil
+}
+
+// AnsweringPlayer requires the user to be the player due to answer a query.
+func AnsweringPlayer(req *Request) error {
+	if err := InGame(req); err != nil {
+		return err
+	}
+
+	if req.Game().AnsweringPlayer() != req.Player() {
+		return game.NotYourTurn
+	}
+
+	return nil
+}
+
+// InStates requires the game to be in one of the given states.
+func InStates(states ...game.State) Precondition {
+	return func(req *Request) error {
+		if err := InGame(req); err != nil {
+			return err
+		}
+
+		current := req.Game().State()
+
+		for _, state := range states {
+			if state == current {
+				return nil
+			}
+		}
+
+		return game.IllegalState.InStates(states...)
+	}
+}
+
+// checkPreconditions returns the error of the first failing precondition of the request handler.
+func checkPreconditions(req *Request) error {
+	for _, precondition := range req.handler.Preconditions() {
+		if err := precondition(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/makeroo/my_clue_be/randomstring"
+)
+
+// registry indexes users, websockets and games.
+// It is shared by the websocket goroutines and the game goroutines: mu guards it along
+// with the User fields, UserIO.user and UserIO.games.
+// mu is held only briefly and never while waiting for a game goroutine.
+type registry struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	// Users that have succesfully signed in.
+	signedUsers map[string]*User
+	// Users that has connected but not yet signed in.
+	connectedUsers []*UserIO
+
+	// All the games, starting or running, this server knows of: the ended ones are dropped,
+	// see Server.SetGameIdleTimeout for the ones nobody follows.
+	games map[string]*serverGame
+}
+
+// game returns the game with the given id, nil if unknown.
+func (r *registry) game(gameID string) *serverGame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.games[gameID]
+}
+
+// onlyFollowedGame returns the id of the game followed by the websocket if it follows
+// exactly one, empty otherwise.
+func (r *registry) onlyFollowedGame(userIO *UserIO) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(userIO.games) != 1 {
+		return ""
+	}
+
+	for gameID := range userIO.games {
+		return gameID
+	}
+
+	return ""
+}
+
+// signedUser returns the user signed in on the websocket, nil if none.
+func (r *registry) signedUser(userIO *UserIO) *User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return userIO.user
+}
+
+// language returns the language of the error messages sent to the websocket.
+func (r *registry) language(userIO *UserIO) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if userIO.user == nil || userIO.user.language == "" {
+		return defaultLanguage
+	}
+
+	return userIO.user.language
+}
+
+// name returns the name of the user.
+func (r *registry) name(user *User) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return user.name
+}
+
+// randomGameToken returns an unused game id. mu must be held.
+func (r *registry) randomGameToken() string {
+	for {
+		t := randomstring.String(r.rand, 4)
+
+		if _, ok := r.games[t]; !ok {
+			return t
+		}
+	}
+}
+
+// randomUserToken returns an unused user token. mu must be held.
+func (r *registry) randomUserToken() string {
+	for {
+		t := randomstring.String(r.rand, 4)
+
+		if r.signedUsers[t] == nil {
+			return t
+		}
+	}
+}
+
+// removeConnectedUser forgets a websocket not signed in. mu must be held.
+func (r *registry) removeConnectedUser(userIO *UserIO) {
+	for i, u := range r.connectedUsers {
+		if u == userIO {
+			r.connectedUsers[i] = r.connectedUsers[len(r.connectedUsers)-1]
+			r.connectedUsers = r.connectedUsers[:len(r.connectedUsers)-1]
+		}
+	}
+}
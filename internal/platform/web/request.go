@@ -2,42 +2,82 @@ package web
 
 import (
 	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/game"
 )
 
 // Request is an incoming request to be served.
 type Request struct {
 	// UserIO is the user who issued the request.
-	UserIO  *UserIO
-	ReqID   int
+	UserIO *UserIO
+	Type   data.MessageType
+	ReqID  int
+	// GameID is the game the request refers to, if any.
+	GameID  string
 	Body    interface{}
 	handler RequestHandler
+
+	// game is the game GameID, if the request is served by its goroutine, see Server.dispatch.
+	game *serverGame
+	// gameUser is the player of game GameID, if the user is following it.
+	gameUser *gameUser
+	// err is set when the request can't be served: the error is sent back instead.
+	err error
+	// errorCode is the code of the error sent back, if any.
+	errorCode game.Error
+}
+
+// Game returns the game the request refers to, nil if the user is not playing it.
+func (req *Request) Game() *game.Game {
+	if req.gameUser == nil {
+		return nil
+	}
+
+	return req.gameUser.player.Game()
+}
+
+// Player returns the player of the user in the game the request refers to, nil if the
+// user is not playing it.
+func (req *Request) Player() *game.Player {
+	if req.gameUser == nil {
+		return nil
+	}
+
+	return req.gameUser.player
+}
+
+// ErrorCode returns the code of the error sent back to the user, empty if none.
+func (req *Request) ErrorCode() game.Error {
+	return req.errorCode
 }
 
-// SendError returns an error message to the user.
+// SendError returns an error message to the user, see data.NotifyError.
 func (req *Request) SendError(err error) {
 	// log.Println("sending err", req.UserIO, err)
 
-	req.UserIO.send <- data.MessageFrame{
+	notify := newNotifyError(err, req.ReqID, req.UserIO.registry.language(req.UserIO))
+
+	req.errorCode = notify.Code
+
+	req.UserIO.deliver(data.MessageFrame{
 		Header: data.MessageHeader{
-			Type:  data.MessageError,
-			ReqID: req.ReqID,
-		},
-		Body: data.NotifyError{
-			// FIXME: actually, Error() is safe only if error is a game.Error...
-			Error: err.Error(),
+			Type:   data.MessageError,
+			ReqID:  req.ReqID,
+			GameID: req.GameID,
 		},
-	}
+		Body: notify,
+	})
 }
 
 // SendMessage returns a response to the user.
 func (req *Request) SendMessage(messageType data.MessageType, body interface{}) {
 	//log.Println("sending msg", req.UserIO, messageType)
 
-	req.UserIO.send <- data.MessageFrame{
+	req.UserIO.deliver(data.MessageFrame{
 		Header: data.MessageHeader{
-			Type:  messageType,
-			ReqID: req.ReqID,
+			Type:   messageType,
+			ReqID:  req.ReqID,
+			GameID: req.GameID,
 		},
 		Body: body,
-	}
+	})
 }
@@ -1,58 +1,122 @@
 package web
 
 import (
+	"encoding/json"
 	"log"
 	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/events"
 	"github.com/makeroo/my_clue_be/internal/platform/game"
-	"github.com/makeroo/my_clue_be/randomstring"
 )
 
-// RequestHandler reads the request payload from the ws and then executes it.
+// RequestHandler decodes the request payload and then executes it.
 type RequestHandler interface {
 	RequestType() data.MessageType
 
-	// BodyReader is a callback that reads expected payload from the ws.
-	// I didn't succede in creating a "generic" readJSON function so everytime I
-	// have to read from a ws I have to know the expected type and parse it.
-	BodyReader(*websocket.Conn) (interface{}, error)
+	// NewBody returns a pointer to an empty payload the request raw json is
+	// decoded into, or nil if the request has no payload.
+	// The decoded payload is then available in Request.Body.
+	NewBody() interface{}
+
+	// Preconditions are checked, in order, before handling the request: the error
+	// of the first failing one is sent back instead.
+	Preconditions() []Precondition
 
 	// Handle implements the logic of a specific request.
+	// Request.Body has the type returned by NewBody.
 	Handle(*Server, *Request)
 }
 
+// OptionalBodyHandler is implemented by the handlers whose payload has been
+// added after the legacy protocol: legacy clients don't send a body frame for
+// them, so Request.Body is left empty.
+type OptionalBodyHandler interface {
+	RequestHandler
+
+	OptionalBody()
+}
+
+// SessionHandler is implemented by the handlers of the requests about the user session
+// rather than a game, eg. sign in: they are served by the websocket goroutine even if
+// their header names a game.
+type SessionHandler interface {
+	RequestHandler
+
+	SessionRequest()
+}
+
+// BodyGameHandler is implemented by the handlers of the requests naming their game in the
+// payload instead of the header, eg. join game.
+type BodyGameHandler interface {
+	RequestHandler
+
+	// BodyGameID returns the id of the game named in the decoded payload.
+	BodyGameID(body interface{}) string
+}
+
 type gameUser struct {
 	user *User
-	io   *UserIO
-	// player is io.player but io is defined only if the user is reachable
+	// io is defined only if the user is reachable and following the game
+	io     *UserIO
 	player *game.Player
+	game   *serverGame
 }
 
+// serverGame is a table served by its own goroutine, see run: it is the only one
+// accessing the game, its players and its journal.
 type serverGame struct {
 	game    *game.Game
 	players []*gameUser
+
+	registry *registry
+	// bus receives the events of the game, published by the game goroutine without waiting for the subscribers.
+	bus *events.Bus
+	// mailbox queues the functions to be executed by the game goroutine.
+	mailbox chan func()
+	// mu guards mailboxClosed: posts hold it for reading, see post and closeMailbox.
+	mu            sync.RWMutex
+	mailboxClosed bool
+	// closing is set by close, closed once the game has been dropped from the registry.
+	closing bool
+	closed  bool
+	// idleTimeout is how long the game is kept when nobody follows it, zero for ever, see closeIfIdle.
+	idleTimeout time.Duration
+	// idleChecks counts the idle checks scheduled, only the last one is valid.
+	idleChecks int
+
+	// seq is the sequence number of the last notification sent to players.
+	seq int
+	// journal keeps the most recent notifications to resend them to players
+	// coming back after a disconnection.
+	journal journal
+	// startSeq is the sequence number of the game started notification, moveSeqs
+	// those of the move records in the order they have been notified.
+	startSeq int
+	moveSeqs []int
+	// answerOptionsSeq is the sequence number of the last answer options notification.
+	answerOptionsSeq int
 }
 
 // Server orchestrates and handles all FE requests.
 type Server struct {
 	upgrader *websocket.Upgrader
-	rand     *rand.Rand
 
 	handlerDescriptors map[data.MessageType]RequestHandler
+	// middlewares wrap every request handler, see Use.
+	middlewares []Middleware
+	// serve is serveRequest wrapped by the middlewares.
+	serve HandlerFunc
 
-	// Users that have succesfully signed in.
-	signedUsers map[string]*User
-	// Users that has connected but not yet signed in.
-	connectedUsers []*UserIO
-
-	register   chan *websocket.Conn
-	unregister chan *UserIO
-	process    chan *Request
+	// registry indexes users, websockets and games.
+	registry registry
+	// bus receives the events of every game, see Events.
+	bus *events.Bus
 
 	maxMessageSize int64
 	pongWait       time.Duration
@@ -61,74 +125,37 @@ type Server struct {
 
 	maxGamesPerPlayer int
 
-	// All the games, starting, running or completed, this server knows of.
-	games map[string]*serverGame
+	// replayWindow is the number of notifications per game kept to be resent.
+	replayWindow int
+	// gameIdleTimeout is how long a game nobody follows is kept, zero for ever.
+	gameIdleTimeout time.Duration
+
+	// queueSize is the number of messages a ws can have waiting to be sent, see overflowPolicy.
+	queueSize      int
+	overflowPolicy OverflowPolicy
 }
 
 // New builds a Server instance.
 func New(upgrader *websocket.Upgrader, rand *rand.Rand) *Server {
 	return &Server{
-		upgrader:          upgrader,
-		rand:              rand,
-		signedUsers:       make(map[string]*User),
-		connectedUsers:    nil,
-		games:             make(map[string]*serverGame),
-		register:          make(chan *websocket.Conn),
-		unregister:        make(chan *UserIO),
-		process:           make(chan *Request),
+		upgrader: upgrader,
+		registry: registry{
+			rand:        rand,
+			signedUsers: make(map[string]*User),
+			games:       make(map[string]*serverGame),
+		},
+		bus:               events.NewBus(1024),
 		maxMessageSize:    1024,
 		pongWait:          60 * time.Second,
 		pingPeriod:        55 * time.Second,
 		writeWait:         10 * time.Second,
 		maxGamesPerPlayer: 10,
+		replayWindow:      256,
+		queueSize:         1024,
+		overflowPolicy:    OverflowResync,
+		serve:             serveRequest,
 
-		handlerDescriptors: map[data.MessageType]RequestHandler{ /*
-				data.MessageVoteStartRequest: {
-					BodyReader: func(ws *websocket.Conn) (interface{}, error) {
-						body := data.VoteStartRequest{}
-						err := ws.ReadJSON(&body)
-						return &body, err
-					},
-					Handler: handlers.HandleVoteStartRequest,
-				},
-				data.MessageRollDicesRequest: {
-					BodyReader: nil,
-					Handler:    handlers.HandleRollDicestRequest,
-				},
-				data.MessageMoveRequest: {
-					BodyReader: func(ws *websocket.Conn) (interface{}, error) {
-						body := data.MoveRequest{}
-						err := ws.ReadJSON(&body)
-						return &body, err
-					},
-					Handler: handlers.HandleMoveRequest,
-				},
-				data.MessageQuerySolutionRequest: {
-					BodyReader: func(ws *websocket.Conn) (interface{}, error) {
-						body := data.QuerySolutionRequest{}
-						err := ws.ReadJSON(&body)
-						return &body, err
-					},
-					Handler: handlers.HandleQuerySolutionRequest,
-				},
-				data.MessageRevealRequest: {
-					BodyReader: func(ws *websocket.Conn) (interface{}, error) {
-						body := data.RevealRequest{}
-						err :¨handlers.HandleRevealRequest,
-				},
-				data.MessagePassRequest: {
-					BodyReader: nil,
-					Handler:    handlers.HandlePassRequest,
-				},
-				data.MessageDeclareSolutionRequest: {
-					BodyReader: func(ws *websocket.Conn) (interface{}, error) {
-						body := data.DeclareSolutionRequest{}
-						err := ws.ReadJSON(&body)
-						return &body, err
-					},
-					Handler: handlers.HandleDeclareSolutionRequest,
-				},*/
-		},
+		handlerDescriptors: map[data.MessageType]RequestHandler{},
 	}
 }
 
@@ -136,26 +163,37 @@ func (server *Server) RegisterHandler(handler RequestHandler) {
 	server.handlerDescriptors[handler.RequestType()] = handler
 }
 
-// Run starts a server.
-func (server *Server) Run() {
-	go func() {
-		for {
-			select {
-			case ws := <-server.register:
-				//log.Println("client to be added")
-				server.addClient(ws)
-				//log.Println("client added")
-			case userIO := <-server.unregister:
-				//log.Println("client to be removed", userIO)
-				server.removeClient(userIO)
-				//log.Println("client removed", userIO)
-			case req := <-server.process:
-				//log.Println("request to be handled", req, req.UserIO)
-				server.handleRequest(req)
-				//log.Println("request handled", req)
-			}
-		}
-	}()
+// Events returns the bus the events of every game are published to.
+// The players don't depend on it, they are notified directly, see NotifyMoves.
+func (server *Server) Events() *events.Bus {
+	return server.bus
+}
+
+// SetOutboundQueue sets the number of messages a ws can have waiting to be sent and what to do
+// when a client is too slow and they are more. It affects the ws connected afterwards.
+func (server *Server) SetOutboundQueue(size int, policy OverflowPolicy) {
+	server.queueSize = size
+	server.overflowPolicy = policy
+}
+
+// SetGameIdleTimeout sets how long a game nobody follows is kept before being dropped, even if
+// it is running: its players can't join it back afterwards. By default, or if timeout is zero,
+// only the ended games are dropped. It affects the games created afterwards.
+func (server *Server) SetGameIdleTimeout(timeout time.Duration) {
+	server.gameIdleTimeout = timeout
+}
+
+// Use wraps every request handler with the given middlewares, the first one being the outermost.
+// The middlewares are executed in the goroutine serving the request, like the handlers:
+// the one of the game or of the websocket, see dispatch. So they run concurrently.
+func (server *Server) Use(middlewares ...Middleware) {
+	server.middlewares = append(server.middlewares, middlewares...)
+
+	server.serve = serveRequest
+
+	for i := len(server.middlewares) - 1; i >= 0; i-- {
+		server.serve = server.middlewares[i](server.serve)
+	}
 }
 
 // Handle receives an HTTP request and upgrade to websocket protocol.
@@ -171,141 +209,146 @@ func (server *Server) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	server.register <- ws
+	server.addClient(ws)
 }
 
 func (server *Server) addClient(conn *websocket.Conn) {
+	protocol := conn.Subprotocol()
+
+	if protocol == "" {
+		protocol = data.ProtocolLegacy
+	}
+
 	userIO := &UserIO{
-		ws:   conn,
-		send: make(chan data.MessageFrame),
+		ws:       conn,
+		protocol: protocol,
+		registry: &server.registry,
+		outbox:   newOutbox(server.queueSize, server.overflowPolicy),
+		closed:   make(chan struct{}),
+		games:    make(map[string]*gameUser),
 	}
 
+	server.registry.mu.Lock()
+	server.registry.connectedUsers = append(server.registry.connectedUsers, userIO)
+	server.registry.mu.Unlock()
+
 	go userIO.writePump(server)
 	go userIO.readPump(server)
-
-	server.connectedUsers = append(server.connectedUsers, userIO)
 }
 
+// removeClient forgets a closed websocket and tells the games it followed that the user is offline.
 func (server *Server) removeClient(userIO *UserIO) {
+	close(userIO.closed)
+
+	r := &server.registry
+
+	r.mu.Lock()
+
 	user := userIO.user
 
 	if user == nil {
 		// disconnected before signin in
-		server.removeConnectedUser(userIO)
+		r.removeConnectedUser(userIO)
+		r.mu.Unlock()
 
 		return
 	}
 
-	log.Println("user disconnected: ", user.token)
-
-	if userIO.player != nil {
-		sg := server.games[userIO.player.Game().ID()]
-
-		// find gameUser and reset
+	followed := make([]*gameUser, 0, len(userIO.games))
 
-		for _, p := range sg.players {
-			if p.io == userIO {
-				p.io = nil
-				break
-			}
-		}
-
-		userState := data.NotifyUserState{
-			ID:        userIO.player.ID(),
-			Name:      user.name,
-			Character: userIO.player.Character(),
-			Online:    false,
-		}
-
-		sg.notifyPlayers(userIO.player, data.MessageNotifyUserState, func(target *game.Player) interface{} {
-			return userState
-		})
+	for _, gu := range userIO.games {
+		followed = append(followed, gu)
 	}
 
+	found := false
+
 	for i, elem := range user.io {
 		if userIO == elem {
 			user.io[i] = user.io[len(user.io)-1]
 			user.io = user.io[:len(user.io)-1]
+			found = true
 
-			return
+			break
 		}
 	}
 
-	log.Println("warning, user not found")
-}
+	token := user.token
 
-func (server *Server) handlerForHeader(msgType data.MessageType) RequestHandler {
-	return server.handlerDescriptors[msgType]
-}
+	r.mu.Unlock()
 
-func (server *Server) handleRequest(req *Request) {
-	req.handler.Handle(server, req)
-}
-
-// CheckStartedGame performs a few check on incoming request.
-func (server *Server) CheckStartedGame(userIO *UserIO) (*game.Game, error) {
-	user := userIO.user
+	log.Println("user disconnected: ", token)
 
-	if user == nil {
-		return nil, game.NotSignedIn
+	if !found {
+		log.Println("warning, user not found")
 	}
 
-	if userIO.player == nil {
-		return nil, game.NotPlaying
-	}
-
-	game := userIO.player.Game()
+	for _, gu := range followed {
+		gu := gu
 
-	return game, nil
+		gu.game.post(func() {
+			gu.game.leave(gu, userIO)
+		})
+	}
 }
 
-// CheckCurrentPlayer verifies that request is issued by current player and returns the game.
-func (server *Server) CheckCurrentPlayer(req *Request) (*game.Game, error) {
-	g, err := server.CheckStartedGame(req.UserIO)
+func (server *Server) handlerForHeader(msgType data.MessageType) RequestHandler {
+	return server.handlerDescriptors[msgType]
+}
 
-	if err != nil {
-		return nil, err
-	}
+// dispatch serves a request: the requests about a game are queued to the game goroutine,
+// so that they are served in the order they are received, the others are served by the
+// calling websocket goroutine. A request not naming its game is about the game followed
+// by the websocket, if it follows exactly one.
+func (server *Server) dispatch(req *Request) {
+	_, session := req.handler.(SessionHandler)
+	_, bodyGame := req.handler.(BodyGameHandler)
 
-	if g.CurrentPlayer() != req.UserIO.player {
-		return nil, game.NotYourTurn
+	if !session && !bodyGame && req.err == nil && req.GameID == "" {
+		req.GameID = server.registry.onlyFollowedGame(req.UserIO)
 	}
 
-	return g, nil
-}
+	if !session && req.err == nil && req.GameID != "" {
+		if sg := server.registry.game(req.GameID); sg != nil {
+			posted := sg.post(func() {
+				if sg.closed {
+					// the game has been closed after the request was dispatched to it
+					req.err = game.UnknownGame
+				} else {
+					req.game = sg
+					req.gameUser = sg.player(req.UserIO)
+				}
 
-// CheckAnsweringPlayer verifies that request is issued by answering player and returns the game.
-func (server *Server) CheckAnsweringPlayer(req *Request) (*game.Game, error) {
-	g, err := server.CheckStartedGame(req.UserIO)
+				server.serve(server, req)
+			})
 
-	if err != nil {
-		return nil, err
-	}
+			if posted {
+				return
+			}
 
-	if g.AnsweringPlayer() != req.UserIO.player {
-		return nil, game.NotYourTurn
+			req.err = game.UnknownGame
+		}
 	}
 
-	return g, nil
+	server.serve(server, req)
 }
 
 // NotifyPlayers broadcast a message to all the players of a given game.
 func (server *Server) NotifyPlayers(g *game.Game, skipPlayer *game.Player, messageType data.MessageType, builder func(*game.Player) interface{}) {
-	sg := server.games[g.ID()]
+	sg := server.registry.game(g.ID())
 
 	sg.notifyPlayers(skipPlayer, messageType, builder)
 }
 
-func (server *Server) removeConnectedUser(userIO *UserIO) {
-	for i, u := range server.connectedUsers {
-		if u == userIO {
-			server.connectedUsers[i] = server.connectedUsers[len(server.connectedUsers)-1]
-			server.connectedUsers = server.connectedUsers[:len(server.connectedUsers)-1]
-		}
-	}
-}
-
 func (g *serverGame) notifyPlayers(skipPlayer *game.Player, message data.MessageType, messageBuilder func(player *game.Player) interface{}) {
+	g.seq++
+
+	g.journal.record(journalEntry{
+		seq:            g.seq,
+		messageType:    message,
+		skipPlayer:     skipPlayer,
+		messageBuilder: messageBuilder,
+	})
+
 	for _, gu := range g.players {
 		if gu.player == skipPlayer {
 			continue
@@ -315,89 +358,216 @@ func (g *serverGame) notifyPlayers(skipPlayer *game.Player, message data.Message
 			continue
 		}
 
-		//log.Println("notify msg gu", gu.io, message)
-
-		gu.io.send <- data.MessageFrame{
+		gu.io.notify(data.MessageFrame{
 			Header: data.MessageHeader{
-				Type: message,
+				Type:   message,
+				GameID: g.game.ID(),
+				Seq:    g.seq,
 			},
 			Body: messageBuilder(gu.player),
-		}
+		})
 	}
 }
 
-func (server *Server) randomGameToken() string {
-	for {
-		t := randomstring.String(server.rand, 4)
+// NotifyGameStarted broadcasts the game started notification to all the players of a given game.
+func (server *Server) NotifyGameStarted(g *game.Game) {
+	sg := server.registry.game(g.ID())
 
-		if _, ok := server.games[t]; !ok {
-			return t
+	sg.notifyPlayers(nil, data.MessageNotifyGameStarted, func(player *game.Player) interface{} {
+		return GameStartedNotification(g, player)
+	})
+
+	sg.startSeq = sg.seq
+}
+
+// NotifyMoves broadcasts move records to all the players of a given game and publishes them,
+// followed by a GameEnded event if the last one ended the game.
+func (server *Server) NotifyMoves(g *game.Game, records ...*game.MoveRecord) {
+	server.registry.game(g.ID()).notifyMoves(records)
+}
+
+func (g *serverGame) notifyMoves(records []*game.MoveRecord) {
+	for _, record := range records {
+		// the journal keeps the builder: each one needs its own record
+		record := record
+
+		g.notifyPlayers(nil, data.MessageNotifyMoveRecord, func(player *game.Player) interface{} {
+			return record.AsMessageFor(player)
+		})
+
+		g.moveSeqs = append(g.moveSeqs, g.seq)
+
+		g.bus.Publish(&events.MoveRecorded{
+			Header: events.Header{
+				GameID: g.game.ID(),
+				Time:   record.Timestamp,
+			},
+			Record: *record,
+		})
+
+		if record.StateDelta.State == game.GameEnded {
+			g.bus.Publish(&events.GameEnded{
+				Header:   events.NewHeader(g.game.ID()),
+				Winner:   record.PlayerID,
+				Solution: *record.StateDelta.Solution,
+				Seed:     *record.StateDelta.Seed,
+			})
+
+			// the players have been notified of the end: nothing is left to serve
+			g.close()
 		}
 	}
 }
 
-func (server *Server) randomUserToken() string {
-	for {
-		t := randomstring.String(server.rand, 4)
+// NotifyAnswerOptions sends the answering player, if any, the cards she/he can reveal.
+func (server *Server) NotifyAnswerOptions(g *game.Game) {
+	server.registry.game(g.ID()).notifyAnswerOptions()
+}
+
+func (g *serverGame) notifyAnswerOptions() {
+	answering := g.game.AnsweringPlayer()
+
+	if answering == nil {
+		return
+	}
+
+	// the options of this query: a replay must not send the ones of a later query
+	options := data.NotifyAnswerOptions{
+		Query: g.game.Query(),
+		Cards: g.game.AnswerOptions(),
+	}
+
+	g.seq++
+	g.answerOptionsSeq = g.seq
+
+	g.journal.record(journalEntry{
+		seq:         g.seq,
+		messageType: data.MessageNotifyAnswerOptions,
+		target:      answering,
+		messageBuilder: func(player *game.Player) interface{} {
+			return options
+		},
+	})
 
-		if server.signedUsers[t] == nil {
-			return t
+	for _, gu := range g.players {
+		if gu.player == answering && gu.io != nil {
+			gu.sendAnswerOptions(options, g.seq)
 		}
 	}
 }
 
+// resendAnswerOptions sends again the last answer options to a player coming back,
+// if she/he is still due to answer.
+func (g *serverGame) resendAnswerOptions(gu *gameUser) {
+	if g.game.AnsweringPlayer() != gu.player {
+		return
+	}
+
+	gu.sendAnswerOptions(data.NotifyAnswerOptions{
+		Query: g.game.Query(),
+		Cards: g.game.AnswerOptions(),
+	}, g.answerOptionsSeq)
+}
+
+func (gu *gameUser) sendAnswerOptions(options data.NotifyAnswerOptions, seq int) {
+	gu.io.notify(data.MessageFrame{
+		Header: data.MessageHeader{
+			Type:   data.MessageNotifyAnswerOptions,
+			GameID: gu.game.game.ID(),
+			Seq:    seq,
+		},
+		Body: options,
+	})
+}
+
+// readPump reads and dispatches the requests until the ws fails or the client has to be
+// disconnected. It doesn't close the ws, the write pump does: writes must not be concurrent.
 func (userIO *UserIO) readPump(server *Server) {
 	ws := userIO.ws
 
-	defer func() {
-		server.unregister <- userIO
-		ws.Close()
-	}()
+	// the write pump sends the close message and then closes the ws
+	defer server.removeClient(userIO)
 
 	ws.SetReadLimit(server.maxMessageSize)
 	ws.SetReadDeadline(time.Now().Add(server.pongWait))
 	ws.SetPongHandler(func(string) error { ws.SetReadDeadline(time.Now().Add(server.pongWait)); return nil })
 
 	for {
-		message := data.MessageHeader{}
-		err := ws.ReadJSON(&message)
+		message := data.Envelope{}
+
+		var err error
+
+		if userIO.protocol == data.ProtocolEnvelope {
+			err = ws.ReadJSON(&message)
+		} else {
+			err = ws.ReadJSON(&message.MessageHeader)
+		}
+
 		if err != nil {
 			log.Println("something went wrong, better to shutdown ws", err)
 			break
 		}
 
-		requestHandler := server.handlerForHeader(message.Type)
+		req := &Request{
+			UserIO: userIO,
+			Type:   message.Type,
+			ReqID:  message.ReqID,
+			GameID: strings.ToUpper(message.GameID),
+		}
+
+		req.handler = server.handlerForHeader(message.Type)
 
-		if requestHandler == nil {
+		if req.handler == nil {
 			log.Println("error: unknown request", message.Type)
+
+			if userIO.protocol == data.ProtocolLegacy {
+				// it is unknown whether a body frame follows: the stream can't be read any further
+				userIO.closeCode = websocket.CloseProtocolError
+				userIO.closeReason = "unknown request"
+				break
+			}
+
+			req.err = game.UnknownRequest
+			server.dispatch(req)
 			continue
 		}
 
-		var body interface{}
+		req.Body = req.handler.NewBody()
 
-		body, err = requestHandler.BodyReader(ws)
+		if req.Body != nil {
+			if _, optional := req.handler.(OptionalBodyHandler); userIO.protocol == data.ProtocolLegacy && !optional {
+				err = ws.ReadJSON(&message.Body)
 
-		if err != nil {
-			log.Println("something went wrong, better to shutdown ws", err)
-			break
-		}
+				if err != nil {
+					log.Println("something went wrong, better to shutdown ws", err)
+					break
+				}
+			}
 
-		server.process <- &Request{
-			UserIO:  userIO,
-			ReqID:   message.ReqID,
-			Body:    body,
-			handler: requestHandler,
+			if len(message.Body) > 0 {
+				if err := json.Unmarshal(message.Body, req.Body); err != nil {
+					log.Println("error: bad request", message.Type, err)
+					req.err = game.BadRequest
+					server.dispatch(req)
+					continue
+				}
+			}
+
+			if bodyGame, ok := req.handler.(BodyGameHandler); ok {
+				req.GameID = strings.ToUpper(bodyGame.BodyGameID(req.Body))
+			}
 		}
 
-		log.Println("delivered", message)
+		server.dispatch(req)
 	}
 }
 
+// writePump writes the queued messages and the pings until the read pump stops or a write
+// fails, then it closes the ws.
 func (userIO *UserIO) writePump(server *Server) {
 	ticker := time.NewTicker(server.pingPeriod)
 
 	ws := userIO.ws
-	user := userIO.user
 
 	defer func() {
 		ticker.Stop()
@@ -406,26 +576,38 @@ func (userIO *UserIO) writePump(server *Server) {
 
 	for {
 		select {
-		case message, ok := <-userIO.send:
-			ws.SetWriteDeadline(time.Now().Add(server.writeWait))
-
-			if err := ws.WriteJSON(message.Header); err != nil {
-				log.Println("user send failed: user=", user, "error=", err)
+		case <-userIO.outbox.ready:
+			if userIO.outbox.isClosing() {
+				userIO.writeClose(server, websocket.CloseTryAgainLater, "too slow")
 				return
 			}
 
-			if message.Body != nil {
-				if err := ws.WriteJSON(message.Body); err != nil {
-					log.Println("user send failed: user=", user, "error=", err)
+			for {
+				message, ok, resync := userIO.outbox.pop()
+
+				if !ok {
+					server.resync(userIO, resync)
+					break
+				}
+
+				ws.SetWriteDeadline(time.Now().Add(server.writeWait))
+
+				if err := userIO.write(message); err != nil {
+					log.Println("user send failed: error=", err)
 					return
 				}
 			}
 
-			if !ok {
-				// The hub closed the channel.
-				return
+		case <-userIO.closed:
+			code := userIO.closeCode
+
+			if code == 0 {
+				code = websocket.CloseNormalClosure
 			}
 
+			userIO.writeClose(server, code, userIO.closeReason)
+			return
+
 		case <-ticker.C:
 			ws.SetWriteDeadline(time.Now().Add(server.writeWait))
 
@@ -436,8 +618,126 @@ func (userIO *UserIO) writePump(server *Server) {
 	}
 }
 
-// NewGame creates a new table.
-func (server *Server) NewGame(userIO *UserIO) (*game.Game, *game.Player, error) {
+// writeClose tells the client the ws is being closed.
+func (userIO *UserIO) writeClose(server *Server, code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+
+	if err := userIO.ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(server.writeWait)); err != nil && err != websocket.ErrCloseSent {
+		log.Println("close message failed: error=", err)
+	}
+}
+
+// resync asks the games to send their full state to a ws whose notifications have been dropped.
+func (server *Server) resync(userIO *UserIO, gameIDs []string) {
+	for _, gameID := range gameIDs {
+		sg := server.registry.game(gameID)
+
+		if sg == nil {
+			userIO.outbox.resynced(gameID, nil)
+			continue
+		}
+
+		// the write pump must not wait for the game goroutine
+		go func(gameID string) {
+			posted := sg.post(func() {
+				sg.resync(userIO)
+			})
+
+			if !posted {
+				userIO.outbox.resynced(gameID, nil)
+			}
+		}(gameID)
+	}
+}
+
+// QueueDepth reports the outbound queue of a ws.
+type QueueDepth struct {
+	User  string `json:"user,omitempty"`
+	Depth int    `json:"depth"`
+	Max   int    `json:"max"`
+}
+
+// QueueDepths reports the outbound queue of every ws, by remote address.
+func (server *Server) QueueDepths() map[string]QueueDepth {
+	r := &server.registry
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	depths := make(map[string]QueueDepth)
+
+	add := func(userIO *UserIO, name string) {
+		depth, max := userIO.outbox.depth()
+
+		depths[userIO.ws.RemoteAddr().String()] = QueueDepth{
+			User:  name,
+			Depth: depth,
+			Max:   max,
+		}
+	}
+
+	for _, userIO := range r.connectedUsers {
+		add(userIO, "")
+	}
+
+	for _, user := range r.signedUsers {
+		for _, userIO := range user.io {
+			add(userIO, user.name)
+		}
+	}
+
+	return depths
+}
+
+// write sends a message according to the negotiated protocol.
+func (userIO *UserIO) write(message data.MessageFrame) error {
+	if userIO.protocol == data.ProtocolLegacy {
+		if err := userIO.ws.WriteJSON(message.Header); err != nil {
+			return err
+		}
+
+		if message.Body == nil {
+			return nil
+		}
+
+		return userIO.ws.WriteJSON(message.Body)
+	}
+
+	envelope := data.Envelope{
+		MessageHeader: message.Header,
+	}
+
+	if message.Body != nil {
+		body, err := json.Marshal(message.Body)
+
+		if err != nil {
+			return err
+		}
+
+		envelope.Body = body
+	}
+
+	return userIO.ws.WriteJSON(envelope)
+}
+
+// NewGame creates a new table and makes the ws follow it.
+// An empty variant name stands for the classic one. If seed is nil a random one is drawn.
+func (server *Server) NewGame(userIO *UserIO, variantName string, rules game.Rules, seed *int64) (*game.Game, *game.Player, error) {
+	variant, ok := game.VariantByName(variantName)
+
+	if !ok {
+		return nil, nil, game.UnknownVariant
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	r := &server.registry
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	user := userIO.user
 
 	if user == nil {
@@ -448,62 +748,117 @@ func (server *Server) NewGame(userIO *UserIO) (*game.Game, *game.Player, error)
 		return nil, nil, game.TooManyGames
 	}
 
-	g := game.New(server.randomGameToken(), server.rand)
+	// a seed id is chosen by clients replaying a game, the others can't be guessed
+	var gameSeed game.Seed
+
+	if seed != nil {
+		gameSeed = game.SeedFromID(*seed)
+	} else {
+		s, err := game.NewSeed()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		gameSeed = s
+	}
+
+	g := game.New(r.randomGameToken(), variant, rules, gameSeed)
 	player, err := g.AddPlayer()
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	sg := &serverGame{
-		game: g,
-	}
+	sg := newServerGame(g, r, server.bus, server.replayWindow, server.gameIdleTimeout)
 
 	gu := &gameUser{
 		user:   user,
 		io:     userIO,
 		player: player,
+		game:   sg,
 	}
 
 	sg.players = append(sg.players, gu)
 
-	server.games[g.ID()] = sg
+	r.games[g.ID()] = sg
 
-	userIO.player = player
+	userIO.games[g.ID()] = gu
 	user.joinedGames = append(user.joinedGames, gu)
 
+	// published by the game goroutine, the mailbox is empty: it does not block
+	created := &events.GameCreated{
+		Header:  events.NewHeader(g.ID()),
+		Variant: variant.Name,
+		Rules:   rules,
+	}
+	joined := &events.PlayerJoined{
+		Header:   events.NewHeader(g.ID()),
+		PlayerID: player.ID(),
+		User:     user.name,
+	}
+
+	sg.post(func() {
+		sg.bus.Publish(created)
+		sg.bus.Publish(joined)
+	})
+
 	return g, player, nil
 }
 
+// GameStartedNotification builds the game started notification sent to a player.
+func GameStartedNotification(g *game.Game, player *game.Player) data.NotifyGameStarted {
+	return data.NotifyGameStarted{
+		PlayersOrder:       g.PlayerTurnSequence(),
+		Deck:               player.Deck(),
+		FaceUp:             g.FaceUpCards(),
+		SolutionCommitment: g.SolutionCommitment(),
+		DealCommitment:     g.DealCommitment(),
+	}
+}
+
 // RunningGames return a an array of synopses of the non completed games joined by the user.
+// It waits for the game goroutines, so it must not be called by one of them.
 func (server *Server) RunningGames(user *User) []data.GameSynopsis {
+	server.registry.mu.Lock()
+	joinedGames := append([]*gameUser(nil), user.joinedGames...)
+	server.registry.mu.Unlock()
+
 	var runningGames []data.GameSynopsis = nil
 
-	for _, gu := range user.joinedGames {
-		runningGames = append(runningGames, server.Synopsis(gu))
+	for _, gu := range joinedGames {
+		gu := gu
+
+		// a closed game is no more running
+		gu.game.call(func() {
+			runningGames = append(runningGames, server.Synopsis(gu))
+		})
 	}
 
 	return runningGames
 }
 
 // Synopsis returns a game synopsis to fill sign in response.
+// It must be called by the game goroutine.
 func (server *Server) Synopsis(targetPlayer *gameUser) data.GameSynopsis {
 	var players []data.GamePlayer = nil
 
-	g := targetPlayer.player.Game()
-	sg := server.games[g.ID()]
+	sg := targetPlayer.game
+	g := sg.game
 
 	for _, gu := range sg.players {
 		players = append(players, data.GamePlayer{
 			Character: gu.player.Character(),
 			ID:        gu.player.ID(),
-			Name:      gu.user.name,
+			Name:      server.registry.name(gu.user),
 			Online:    gu.io != nil,
 		})
 	}
 
 	synopsis := data.GameSynopsis{
 		ID:      g.ID(),
+		Variant: g.Variant().Name,
+		Rules:   g.Rules(),
 		Game:    g.FullState(targetPlayer.player.ID()),
 		MyID:    targetPlayer.player.ID(),
 		Players: players,
@@ -513,79 +868,102 @@ func (server *Server) Synopsis(targetPlayer *gameUser) data.GameSynopsis {
 }
 
 // State return the player state to be notified to the f/e.
+// It must be called by the game goroutine.
 func (user *gameUser) State() data.NotifyUserState {
 	return data.NotifyUserState{
 		ID:        user.player.ID(),
-		Name:      user.user.name,
+		Name:      user.game.registry.name(user.user),
 		Character: user.player.Character(),
 		Online:    user.io != nil,
 	}
 }
 
-// JoinGame assign add a player to the given game.
-func (server *Server) JoinGame(gameID string, userIO *UserIO) (*data.JoinGameResponse, error) {
-	user := userIO.user
+// JoinGame assign add a player to the given game and makes the ws follow it.
+// The game is the one the request has been dispatched to, see BodyGameHandler.
+func (server *Server) JoinGame(req *Request) (*data.JoinGameResponse, error) {
+	sg := req.game
 
-	if user == nil {
-		return nil, game.NotSignedIn
+	if sg == nil {
+		return nil, game.UnknownGame
 	}
 
-	if userIO.player != nil {
-		// TODO: what about changing game inside a tab?
-		// workaround: close and repone ws
-		return nil, game.AlreadyPlaying
-	}
+	userIO := req.UserIO
+	r := &server.registry
 
-	sg, ok := server.games[strings.ToUpper(gameID)]
+	r.mu.Lock()
 
-	if !ok {
-		return nil, game.UnknownGame
-	}
+	user := userIO.user
+
+	var rUser *gameUser = nil
 
-	var rPlayer *game.Player = nil
+	if user != nil {
+		for _, gu := range user.joinedGames {
+			if gu.game == sg {
+				rUser = gu
 
-	for _, gu := range user.joinedGames {
-		if gu.player.Game().ID() == gameID {
-			if gu.io != nil {
-				// no more than 1 tab(ws) per game
-				return nil, game.AlreadyPlaying
+				break
 			}
+		}
+	}
 
-			// recover an already running game
-			// ie. user disconnected for some reason and know she/he has come back!
+	r.mu.Unlock()
 
-			gu.io = userIO
-			userIO.player = gu.player
-			userIO.game = sg
+	if user == nil {
+		return nil, game.NotSignedIn
+	}
 
-			rPlayer = gu.player
+	returning := rUser != nil
 
-			break
+	if returning {
+		if rUser.io != nil {
+			// no more than 1 tab(ws) per game
+			return nil, game.AlreadyPlaying
 		}
-	}
 
-	if rPlayer == nil {
-		var err error
+		// recover an already running game
+		// ie. user disconnected for some reason and know she/he has come back!
 
-		rPlayer, err = sg.game.AddPlayer()
+		rUser.io = userIO
+
+	} else {
+		player, err := sg.game.AddPlayer()
 
 		if err != nil {
 			return nil, err
 		}
 
-		gu := &gameUser{
+		rUser = &gameUser{
 			user:   user,
 			io:     userIO,
-			player: rPlayer,
+			player: player,
+			game:   sg,
 		}
 
-		sg.players = append(sg.players, gu)
+		sg.players = append(sg.players, rUser)
+
+		r.mu.Lock()
+		user.joinedGames = append(user.joinedGames, rUser)
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	userIO.games[sg.game.ID()] = rUser
+	r.mu.Unlock()
 
-		userIO.player = rPlayer
-		userIO.game = sg
-		user.joinedGames = append(user.joinedGames, gu)
+	sg.bus.Publish(&events.PlayerJoined{
+		Header:    events.NewHeader(sg.game.ID()),
+		PlayerID:  rUser.player.ID(),
+		User:      r.name(user),
+		Returning: returning,
+	})
+
+	if userIO.isClosed() {
+		// the ws has been closed meanwhile, maybe too early for removeClient to know of this game
+		sg.leave(rUser, userIO)
 	}
 
+	req.gameUser = rUser
+
 	players := make([]data.NotifyUserState, len(sg.players))
 
 	for i, gu := range sg.players {
@@ -593,62 +971,89 @@ func (server *Server) JoinGame(gameID string, userIO *UserIO) (*data.JoinGameRes
 	}
 
 	return &data.JoinGameResponse{
+		GameID:  sg.game.ID(),
+		Variant: sg.game.Variant().Name,
+		Rules:   sg.game.Rules(),
 		Players: players,
-		MyID:    rPlayer.ID(),
+		MyID:    rUser.player.ID(),
+		Seq:     sg.seq,
 	}, nil
 }
 
 // CompleteJoin sends game state to newly joined player and notifies the others.
+// If the player is coming back and lastSeq is recent enough only the missed
+// notifications are sent, otherwise the whole game state.
 // FIXME: JoinRequest handler is ugly maybe I should split join in two requests
-func (server *Server) CompleteJoin(userIO *UserIO) {
-	sg := userIO.game
+func (server *Server) CompleteJoin(req *Request, lastSeq int) {
+	gu := req.gameUser
+	sg := gu.game
+	userIO := req.UserIO
+
+	replay := lastSeq > 0 && sg.canReplay(lastSeq)
+
+	switch {
+	case replay:
+		sg.replay(gu, lastSeq)
 
-	if sg.game.Started() {
-		userIO.send <- data.MessageFrame{
+	case lastSeq > 0:
+		userIO.notify(data.MessageFrame{
 			Header: data.MessageHeader{
-				Type: data.MessageNotifyGameStarted,
+				Type:   data.MessageNotifyFullState,
+				GameID: sg.game.ID(),
+				Seq:    sg.seq,
 			},
-			Body: data.NotifyGameStarted{
-				PlayersOrder: sg.game.PlayerTurnSequence(),
-				Deck:         userIO.player.Deck(),
+			Body: sg.fullState(gu),
+		})
+
+	case sg.game.Started():
+		userIO.notify(data.MessageFrame{
+			Header: data.MessageHeader{
+				Type:   data.MessageNotifyGameStarted,
+				GameID: sg.game.ID(),
+				Seq:    sg.startSeq,
 			},
-		}
+			Body: GameStartedNotification(sg.game, gu.player),
+		})
+
+		i := 0
 
 		sg.game.History(func(record game.MoveRecord) {
-			userIO.send <- data.MessageFrame{
+			// each record with the seq it has been notified with
+			seq := sg.seq
+
+			if i < len(sg.moveSeqs) {
+				seq = sg.moveSeqs[i]
+			}
+
+			i++
+
+			userIO.notify(data.MessageFrame{
 				Header: data.MessageHeader{
-					Type: data.MessageNotifyMoveRecord,
+					Type:   data.MessageNotifyMoveRecord,
+					GameID: sg.game.ID(),
+					Seq:    seq,
 				},
-				Body: record.AsMessageFor(userIO.player),
-			}
+				Body: record.AsMessageFor(gu.player),
+			})
 		})
 	}
 
-	message := data.NotifyUserState{
-		ID:        userIO.player.ID(),
-		Character: userIO.player.Character(),
-		Name:      userIO.user.name,
-		Online:    true,
+	if !replay {
+		// a replay includes the answer options
+		sg.resendAnswerOptions(gu)
 	}
 
-	sg.notifyPlayers(userIO.player, data.MessageNotifyUserState, func(player *game.Player) interface{} {
+	message := gu.State()
+
+	sg.notifyPlayers(gu.player, data.MessageNotifyUserState, func(player *game.Player) interface{} {
 		return message
 	})
 }
 
 // SelectCharacter assign given character to player provided any other player has not selected it yet.
-func (server *Server) SelectCharacter(userIO *UserIO, character game.Card) (*data.NotifyUserState, error) {
-	if userIO.player == nil {
-		return nil, game.NotPlaying
-	}
-
-	if userIO.user == nil {
-		return nil, game.NotSignedIn
-	}
-
-	game := userIO.player.Game()
-
-	notify, err := game.SelectCharacter(userIO.player, character)
+// The request must have passed the InGame precondition.
+func (server *Server) SelectCharacter(req *Request, character game.Card) (*data.NotifyUserState, error) {
+	notify, err := req.Game().SelectCharacter(req.gameUser.player, character)
 
 	if err != nil {
 		return nil, err
@@ -658,23 +1063,29 @@ func (server *Server) SelectCharacter(userIO *UserIO, character game.Card) (*dat
 		return nil, nil
 	}
 
-	return &data.NotifyUserState{
-		ID:        userIO.player.ID(),
-		Character: userIO.player.Character(),
-		Name:      userIO.user.name,
-		Online:    true,
-	}, nil
+	req.game.bus.Publish(&events.CharacterSelected{
+		Header:    events.NewHeader(req.game.game.ID()),
+		PlayerID:  req.gameUser.player.ID(),
+		Character: character,
+	})
+
+	newState := req.gameUser.State()
+
+	return &newState, nil
 }
 
-// VoteStart acknowledges player vote and start the game if every player is ready.
-func (server *Server) VoteStart(userIO *UserIO, vote bool) (*game.Game, error) {
-	g, err := server.CheckStartedGame(userIO)
+// SetAutoAnswer sets the player auto answer preference, see game.SetAutoAnswer.
+// The request must have passed the InGame precondition.
+func (server *Server) SetAutoAnswer(req *Request, autoAnswer bool) ([]*game.MoveRecord, error) {
+	return req.Game().SetAutoAnswer(req.gameUser.player, autoAnswer)
+}
 
-	if err != nil {
-		return nil, err
-	}
+// VoteStart acknowledges player vote and start the game if every player is ready.
+// The request must have passed the InGame precondition.
+func (server *Server) VoteStart(req *Request, vote bool) (*game.Game, error) {
+	g := req.Game()
 
-	started, err := g.VoteStart(userIO.player, vote)
+	started, err := g.VoteStart(req.gameUser.player, vote)
 
 	if err != nil {
 		return nil, err
@@ -684,7 +1095,14 @@ func (server *Server) VoteStart(userIO *UserIO, vote bool) (*game.Game, error) {
 		return nil, nil
 	}
 
-	g.Start()
+	if err := g.Start(); err != nil {
+		return nil, err
+	}
+
+	req.game.bus.Publish(&events.GameStarted{
+		Header:       events.NewHeader(g.ID()),
+		PlayersOrder: g.PlayerTurnSequence(),
+	})
 
 	return g, nil
 }
@@ -0,0 +1,190 @@
+package web_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/makeroo/my_clue_be/client"
+	"github.com/makeroo/my_clue_be/internal/platform/data"
+	"github.com/makeroo/my_clue_be/internal/platform/web"
+	"github.com/makeroo/my_clue_be/internal/platform/web/handlers"
+)
+
+// newTestServer serves the websocket protocol with every request handler registered.
+func newTestServer(t *testing.T) client.Options {
+	upgrader := websocket.Upgrader{Subprotocols: data.Protocols}
+
+	server := web.New(&upgrader, rand.New(rand.NewSource(1)))
+
+	for _, handler := range handlers.All() {
+		server.RegisterHandler(handler)
+	}
+
+	server.Use(web.Recover)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.Handle))
+
+	t.Cleanup(httpServer.Close)
+
+	return client.Options{URL: "ws" + strings.TrimPrefix(httpServer.URL, "http")}
+}
+
+// checkSeqs receives the notifications of a client until it is closed, checking that the ones
+// of each game are in order.
+func checkSeqs(t *testing.T, c *client.Client, readers *sync.WaitGroup) {
+	defer readers.Done()
+
+	last := map[string]int{}
+
+	for notification := range c.Notifications() {
+		if notification.Seq <= last[notification.GameID] {
+			t.Errorf("notification %d of game %s after %d", notification.Seq, notification.GameID, last[notification.GameID])
+		}
+
+		last[notification.GameID] = notification.Seq
+	}
+}
+
+func TestConcurrentTables(t *testing.T) {
+	const tables = 20
+	const turns = 10
+
+	opts := newTestServer(t)
+
+	var wg, readers sync.WaitGroup
+	var played atomic.Int64
+
+	for table := 0; table < tables; table++ {
+		wg.Add(1)
+
+		go func(table int) {
+			defer wg.Done()
+
+			var players []*client.Client
+
+			for _, name := range []string{"alice", "bob"} {
+				c, err := client.Dial(opts)
+
+				if err != nil {
+					t.Error("dial failed:", err)
+					return
+				}
+
+				defer c.Close()
+
+				readers.Add(1)
+				go checkSeqs(t, c, &readers)
+
+				if _, err := c.SignIn(fmt.Sprint(name, table)); err != nil {
+					t.Error("sign in failed:", err)
+					return
+				}
+
+				players = append(players, c)
+			}
+
+			alice, bob := players[0], players[1]
+
+			created, err := alice.CreateGameWithSeed(int64(table))
+
+			if err != nil {
+				t.Error("create game failed:", err)
+				return
+			}
+
+			gameID := created.GameID
+
+			steps := []func() error{
+				func() error { _, err := bob.JoinGame(gameID); return err },
+				func() error { return alice.SelectCharacter(gameID, client.ProfPlum) },
+				func() error { return bob.SelectCharacter(gameID, client.MrsWhite) },
+				func() error { return alice.VoteStart(gameID, true) },
+				func() error { return bob.VoteStart(gameID, true) },
+			}
+
+			for _, step := range steps {
+				if err := step(); err != nil {
+					t.Error("game setup failed:", err)
+					return
+				}
+			}
+
+			for turn := 0; turn < turns; turn++ {
+				// only the current player can roll the dices
+				for _, c := range players {
+					if err := c.RollDices(gameID); err != nil {
+						continue
+					}
+
+					if err := c.StopMoving(gameID); err != nil {
+						t.Error("stop moving failed:", err)
+						return
+					}
+
+					if err := c.Pass(gameID); err != nil {
+						t.Error("pass failed:", err)
+						return
+					}
+
+					played.Add(1)
+
+					break
+				}
+			}
+		}(table)
+	}
+
+	wg.Wait()
+	readers.Wait()
+
+	if played.Load() != tables*turns {
+		t.Errorf("played %d turns, want %d", played.Load(), tables*turns)
+	}
+}
+
+func TestRequestWithoutGameID(t *testing.T) {
+	opts := newTestServer(t)
+
+	c, err := client.Dial(opts)
+
+	if err != nil {
+		t.Fatal("dial failed:", err)
+	}
+
+	defer c.Close()
+
+	go func() {
+		for range c.Notifications() {
+		}
+	}()
+
+	if _, err := c.SignIn("alice"); err != nil {
+		t.Fatal("sign in failed:", err)
+	}
+
+	if _, err := c.CreateGame(); err != nil {
+		t.Fatal("create game failed:", err)
+	}
+
+	// the only game followed
+	if err := c.SelectCharacter("", client.ProfPlum); err != nil {
+		t.Error("select character without game id failed:", err)
+	}
+
+	if _, err := c.CreateGame(); err != nil {
+		t.Fatal("create game failed:", err)
+	}
+
+	// which one?
+	if err := c.SelectCharacter("", client.MrsWhite); !errors.Is(err, client.NotPlaying) {
+		t.Errorf("select character without game id returned %v with two games followed, want %s", err, client.NotPlaying)
+	}
+}
@@ -1,25 +1,35 @@
 package web
 
 import (
+	"log"
+
 	"github.com/gorilla/websocket"
 	"github.com/makeroo/my_clue_be/internal/platform/data"
-	"github.com/makeroo/my_clue_be/internal/platform/game"
 )
 
 // UserIO collects data to handle ws I/O.
 // There is an instance per websocket/browser tab.
-// A user can have multiple tab/windows running different games.
-// Each tab is binded to one game at most though.
-// A use is not allowed to have more than one tab/window opened on the same game.
+// A user can have multiple tab/windows, and each of them can follow many games
+// at once: requests and notifications are routed by the game id in their header.
+// A use is not allowed to have more than one tab/window following the same game.
 type UserIO struct {
-	ws   *websocket.Conn
-	send chan data.MessageFrame
+	ws *websocket.Conn
+	// protocol is the subprotocol negotiated on connection, see data.Protocols.
+	protocol string
+	registry *registry
+	outbox   *outbox
+	// closed is closed when the read pump stops: messages are no longer delivered and
+	// the write pump closes the ws.
+	closed chan struct{}
+	// closeCode and closeReason are sent by the write pump in the close message, see readPump.
+	closeCode   int
+	closeReason string
 
 	// user is defined after a sign in request
 	user *User
-	// player and game are defined after a create or join game request
-	player *game.Player
-	game   *serverGame
+	// games are the tables followed by this ws, indexed by game id.
+	// A game is added after a create or join game request.
+	games map[string]*gameUser
 }
 
 // User collects all the info to recognize a user and to allow her/him to play Clue.
@@ -28,9 +38,41 @@ type User struct {
 	name string
 	// Token is the secret that used to recognize a user.
 	token string
+	// language is the language of the error messages, empty for the default one.
+	language string
 
 	// io is a collection of all opened websockets of a user.
 	io []*UserIO
 
 	joinedGames []*gameUser
 }
+
+// deliver queues a response to be sent to the ws.
+func (userIO *UserIO) deliver(message data.MessageFrame) {
+	userIO.push(queuedFrame{MessageFrame: message})
+}
+
+// notify queues a game notification to be sent to the ws, see OverflowPolicy.
+func (userIO *UserIO) notify(message data.MessageFrame) {
+	userIO.push(queuedFrame{MessageFrame: message, notification: true})
+}
+
+func (userIO *UserIO) push(frame queuedFrame) {
+	if userIO.isClosed() {
+		return
+	}
+
+	if !userIO.outbox.push(frame) {
+		log.Println("outbound queue overflow, closing ws: remote=", userIO.ws.RemoteAddr())
+	}
+}
+
+// isClosed returns true if the ws has been closed.
+func (userIO *UserIO) isClosed() bool {
+	select {
+	case <-userIO.closed:
+		return true
+	default:
+		return false
+	}
+}